@@ -1,23 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"github.com/vektah/gqlparser/v2"
-	"github.com/vektah/gqlparser/v2/ast"
-	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"recodegen"
 	"recodegen/config"
-	"recodegen/typescript"
+	"recodegen/diff"
+	"recodegen/errs"
+	"recodegen/schema"
 	"runtime"
+	"strings"
 )
 
 const VERSION = "v0.4.4"
 
 func main() {
-	configFileName := flag.String("config", "recodegen.json", "Configuration file name")
+	configFileName := flag.String("config", "", "Configuration file name, JSON or YAML, "+
+		"if not set the first existing of "+strings.Join(config.DefaultFileNames, ", ")+" is used")
 	versionFlag := flag.Bool("v", false, "Print version")
+	jobs := flag.Int("j", runtime.NumCPU(), "Number of output files generated in parallel")
+	watchFlag := flag.Bool("watch", false, "Keep running and regenerate outputs when schema or documents change")
+	offlineFlag := flag.Bool("offline", false, "Load schema URLs from cache without sending requests")
+	checkFlag := flag.Bool("check", false, "Don't write anything, print diff of outdated outputs and fail if there are any")
 	flag.Parse()
 
 	if *versionFlag {
@@ -25,133 +34,195 @@ func main() {
 		os.Exit(0)
 	}
 
+	var err error
 	if *configFileName == "" {
-		*configFileName = "recodegen.json"
-	}
-	cliConfig := config.ReadConfigFromFile(*configFileName)
-	schemaAst := getSchemaAst(cliConfig.Schema)
-
-	for outputFileName, genConfig := range cliConfig.Generates {
-		processInput(schemaAst, outputFileName, &genConfig)
-	}
-
-	//fmt.Println("Parallel")
-	//var wg sync.WaitGroup
-	//
-	//for outputFileName, genConfig := range cliConfig.Generates {
-	//	// increment the WaitGroup counter
-	//	wg.Add(1)
-	//
-	//	go func(schemaAst *ast.Schema, outputFileName string, genConfig config.CodegenSchemaEntryConfig) {
-	//		// decrement the WaitGroup counter when the goroutine completes
-	//		defer wg.Done()
-	//
-	//		processInput(schemaAst, outputFileName, genConfig)
-	//	}(schemaAst, outputFileName, genConfig)
-	//}
-	//
-	//// wait for all goroutines to finish
-	//wg.Wait()
+		*configFileName, err = config.FindConfigFile()
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+	cliConfig, err := config.ReadConfigFromFile(*configFileName)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	generator := recodegen.Generator{
+		Config: cliConfig,
+		Jobs:   *jobs,
+		SchemaLoader: schema.Loader{
+			Offline: *offlineFlag,
+		},
+	}
+
+	if *watchFlag {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		regenerate(ctx, &generator, nil)
+		watch(ctx, &generator)
+		return
+	}
+
+	results, err := generator.Run(context.Background())
+	if err != nil {
+		exitWithError(err)
+	}
+	if *checkFlag {
+		isStale, err := checkResults(results)
+		if err != nil {
+			os.Exit(exitCode(err))
+		}
+		if isStale {
+			os.Exit(exitCodeStale)
+		}
+		return
+	}
+	if err := writeResults(results); err != nil {
+		os.Exit(exitCode(err))
+	}
 
 	PrintMemUsage()
 }
 
-func processInput(schemaAst *ast.Schema, outputFileName string, genConfig *config.CodegenSchemaEntryConfig) {
-	output := ""
-	hadKnownPlugin := false
-	for _, plugin := range genConfig.Plugins {
-		if plugin == "typescript" {
-			hadKnownPlugin = true
-			schema := typescript.Schema{Ast: schemaAst}
-			output += schema.String()
+// writeResults writes all results and prints their statuses, the first error is returned
+func writeResults(results []recodegen.Result) error {
+	var firstErr error
+	for _, result := range results {
+		status, err := writeResult(result)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			fmt.Fprintf(os.Stderr, "[error] %s\n%s\n", result.FileName, err)
+			continue
 		}
+		fmt.Println(status)
+	}
+	return firstErr
+}
 
-		if plugin == "typescript-operations" {
-			hadKnownPlugin = true
-			operation := typescript.Operations{
-				Ast:    schemaAst,
-				Config: genConfig,
+// checkResults prints diff of every output which differs from the existing file without writing anything,
+// returns true if any output is outdated and the first error
+func checkResults(results []recodegen.Result) (bool, error) {
+	var firstErr error
+	isStale := false
+	for _, result := range results {
+		if result.Err != nil {
+			if firstErr == nil {
+				firstErr = result.Err
 			}
-
-			output += operation.String()
+			fmt.Fprintf(os.Stderr, "[error] %s\n%s\n", result.FileName, result.Err)
+			continue
 		}
+		if !result.IsSupported {
+			continue
+		}
+		existingFileContent := getFileContentIfExists(result.FileName)
+		if *existingFileContent == result.Content {
+			fmt.Printf("[unchanged] %s\n", result.FileName)
+			continue
+		}
+		// such file wouldn't be written, so it's not outdated
+		if !result.Overwrite && fileExists(result.FileName) {
+			fmt.Println(skippedStatus(result.FileName))
+			continue
+		}
+		isStale = true
+		fmt.Printf("[outdated] %s\n", result.FileName)
+		fmt.Print(diff.Unified(result.FileName, result.FileName+" (generated)", *existingFileContent, result.Content))
+	}
+	return isStale, firstErr
+}
+
+// writeResult writes generated content to the output file if it has changed and returns status to print
+func writeResult(result recodegen.Result) (string, error) {
+	if result.Err != nil {
+		return "", result.Err
 	}
 
 	// don't write anything to a file if no known plugins were used
-	if !hadKnownPlugin {
-		fmt.Printf("[unsupported] %s\n", outputFileName)
-		return
+	if !result.IsSupported {
+		return fmt.Sprintf("[unsupported] %s", result.FileName), nil
 	}
 
-	existingFileContent := getFileContentIfExists(outputFileName)
-	if *existingFileContent != output {
-		fmt.Printf("[writing] %s\n", outputFileName)
-		writeFile(outputFileName, output)
-	} else {
-		fmt.Printf("[unchanged] %s\n", outputFileName)
+	existingFileContent := getFileContentIfExists(result.FileName)
+	if *existingFileContent != result.Content {
+		if !result.Overwrite && fileExists(result.FileName) {
+			return skippedStatus(result.FileName), nil
+		}
+		if err := writeFile(result.FileName, result.Content); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[writing] %s", result.FileName), nil
 	}
+	return fmt.Sprintf("[unchanged] %s", result.FileName), nil
 }
 
-func getFileContentIfExists(fileName string) *string {
-	schemaBytes, err := os.ReadFile(fileName)
-	if err != nil {
-		empty := ""
-		return &empty
+// exit codes by error type, so scripts can tell what went wrong
+const (
+	exitCodeError         = 1
+	exitCodeConfigError   = 2
+	exitCodeSchemaError   = 3
+	exitCodeDocumentError = 4
+	exitCodeIOError       = 5
+	// -check found outputs which differ from generated content
+	exitCodeStale = 6
+)
+
+func exitCode(err error) int {
+	var configErr *errs.ConfigError
+	var schemaErr *errs.SchemaError
+	var documentErr *errs.DocumentError
+	var ioErr *errs.IOError
+	switch {
+	case errors.As(err, &configErr):
+		return exitCodeConfigError
+	case errors.As(err, &schemaErr):
+		return exitCodeSchemaError
+	case errors.As(err, &documentErr):
+		return exitCodeDocumentError
+	case errors.As(err, &ioErr):
+		return exitCodeIOError
 	}
+	return exitCodeError
+}
 
-	content := string(schemaBytes)
-	return &content
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+	os.Exit(exitCode(err))
 }
 
-func getFileContent(fileName string) string {
-	schemaBytes, err := os.ReadFile(fileName)
-	if err != nil {
-		panic(err)
-	}
+func skippedStatus(fileName string) string {
+	return fmt.Sprintf("[skipped] %s - file exists and overwrite is false", fileName)
+}
 
-	return string(schemaBytes)
+func fileExists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
 }
 
-func getSchemaAst(inputFileName string) *ast.Schema {
-	schemaStr := getFileContent(inputFileName)
-	source := &ast.Source{
-		Name:  inputFileName,
-		Input: schemaStr,
+func getFileContentIfExists(fileName string) *string {
+	schemaBytes, err := os.ReadFile(fileName)
+	if err != nil {
+		empty := ""
+		return &empty
 	}
 
-	// Parse the schema file
-	schemaAst, parseErr := gqlparser.LoadSchema(source)
-	if parseErr != nil {
-		panic(parseErr)
-	}
-	return schemaAst
+	content := string(schemaBytes)
+	return &content
 }
 
-func writeFile(fileName string, data string) {
+func writeFile(fileName string, data string) error {
 	dir := filepath.Dir(fileName)
 	err := os.MkdirAll(dir, os.ModePerm)
 	if err != nil {
-		log.Fatal(err)
+		return &errs.IOError{Path: dir, Err: err}
 	}
 
-	f, err := os.Create(fileName)
-
+	err = os.WriteFile(fileName, []byte(data), 0666)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(f)
-
-	_, err2 := f.WriteString(data)
-
-	if err2 != nil {
-		log.Fatal(err2)
+		return &errs.IOError{Path: fileName, Err: err}
 	}
+	return nil
 }
 
 func PrintMemUsage() {
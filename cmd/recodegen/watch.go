@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"recodegen"
+	"recodegen/document"
+	"recodegen/schema"
+	"strings"
+	"time"
+)
+
+const (
+	// how often files are checked for changes
+	watchInterval = 500 * time.Millisecond
+	// regeneration starts when there were no more changes for this long, so saving many files regenerates once
+	watchDebounce = 300 * time.Millisecond
+)
+
+// fileState is used to detect changes of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchState is a state of watched files and of directories where files matching globs can appear
+type watchState struct {
+	files map[string]fileState
+	dirs  map[string]fileState
+}
+
+// watch regenerates affected outputs every time the schema or files matching documents globs change,
+// it runs until ctx is cancelled. Errors are printed and watching continues.
+// Globs are matched again only when a directory they cover changes, otherwise only known files are checked.
+// Changes are kept if schema or documents can't be loaded, they are regenerated with the next change
+func watch(ctx context.Context, generator *recodegen.Generator) {
+	state := scanWatched(generator)
+	fmt.Println("[watching] for schema and documents changes")
+
+	var changed []string
+	var lastChange, lastAttempt time.Time
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		newState := watchState{files: statFiles(state.files), dirs: state.dirs}
+		if len(changedFiles(state.dirs, statFiles(state.dirs))) > 0 {
+			// files were added, removed or renamed
+			newState = scanWatched(generator)
+		}
+		if newChanged := changedFiles(state.files, newState.files); len(newChanged) > 0 {
+			changed = append(changed, newChanged...)
+			lastChange = time.Now()
+		}
+		state = newState
+		if len(changed) == 0 || time.Since(lastChange) < watchDebounce || lastAttempt.After(lastChange) {
+			continue
+		}
+
+		lastAttempt = time.Now()
+		if regenerate(ctx, generator, changed) {
+			changed = nil
+		}
+	}
+}
+
+// regenerate reloads schema if schema files changed and documents if document files changed,
+// then generates outputs which depend on changed files. Everything is loaded and generated if nothing was loaded before.
+// False is returned if schema or documents can't be loaded
+func regenerate(ctx context.Context, generator *recodegen.Generator, changed []string) bool {
+	previousDocuments := generator.Documents()
+	isSchemaChanged, isDocumentsChanged := previousDocuments == nil, previousDocuments == nil
+	for _, fileName := range changed {
+		isSchemaChanged = isSchemaChanged || document.Match(generator.Config.Schema.Paths(), fileName)
+		isDocumentsChanged = isDocumentsChanged || document.Match(generator.DocumentPatterns(), fileName)
+	}
+	if isSchemaChanged {
+		if err := generator.LoadSchema(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+			return false
+		}
+	}
+	if isDocumentsChanged {
+		if err := generator.LoadDocuments(); err != nil {
+			fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+			return false
+		}
+	}
+
+	outputFileNames := generator.OutputFileNames()
+	if previousDocuments != nil {
+		outputFileNames = affectedOutputs(generator, previousDocuments, changed)
+	}
+	if len(outputFileNames) > 0 {
+		writeResults(generator.GenerateOutputs(ctx, outputFileNames))
+	}
+	return true
+}
+
+// affectedOutputs returns outputs which depend on changed files. Schema change affects all outputs,
+// document change affects outputs with matching documents globs. Fragments can be used by any output,
+// so if changed file has fragments before or after the change, all outputs with documents are affected
+func affectedOutputs(generator *recodegen.Generator, previousDocuments *document.Set, changed []string) []string {
+	var outputFileNames []string
+	for _, outputFileName := range generator.OutputFileNames() {
+		patterns := generator.Config.Generates[outputFileName].Documents.Paths()
+		for _, fileName := range changed {
+			isSchema := document.Match(generator.Config.Schema.Paths(), fileName)
+			isDocument := document.Match(patterns, fileName)
+			hasFragments := len(patterns) > 0 &&
+				(previousDocuments.HasFragments(fileName) || generator.Documents().HasFragments(fileName))
+			if isSchema || isDocument || hasFragments {
+				outputFileNames = append(outputFileNames, outputFileName)
+				break
+			}
+		}
+	}
+	return outputFileNames
+}
+
+// scanWatched matches globs to find schema files and all files matching documents globs,
+// directories covered by the globs are remembered to find out when files are added or removed
+func scanWatched(generator *recodegen.Generator) watchState {
+	var patterns []string
+	for _, pattern := range generator.Config.Schema.Paths() {
+		if !schema.IsURL(pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	patterns = append(patterns, generator.DocumentPatterns()...)
+
+	schemaFileNames, _ := schema.FindFiles(generator.Config.Schema.Paths())
+	fileNames := append(schemaFileNames, document.FindFiles(generator.DocumentPatterns())...)
+	return watchState{
+		files: statFiles(toSet(fileNames)),
+		dirs:  statFiles(toSet(watchedDirs(patterns))),
+	}
+}
+
+// watchedDirs returns directories where files matching patterns can appear. Pattern with "**" covers
+// all directories under its base like "src" of "src/**/*.ts", other patterns cover directories matching
+// their directory part like "src/*" of "src/*/query.ts"
+func watchedDirs(patterns []string) []string {
+	var dirs []string
+	fsys := os.DirFS(".")
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "**") {
+			matches, _ := doublestar.Glob(fsys, path.Dir(pattern))
+			dirs = append(dirs, matches...)
+			continue
+		}
+		base, _ := doublestar.SplitPattern(pattern)
+		filepath.WalkDir(base, func(fileName string, entry fs.DirEntry, err error) error {
+			if err == nil && entry.IsDir() {
+				dirs = append(dirs, fileName)
+			}
+			return nil
+		})
+	}
+	return dirs
+}
+
+func toSet(fileNames []string) map[string]fileState {
+	set := make(map[string]fileState, len(fileNames))
+	for _, fileName := range fileNames {
+		set[fileName] = fileState{}
+	}
+	return set
+}
+
+// statFiles returns current state of the given files, files which don't exist anymore are left out
+func statFiles(files map[string]fileState) map[string]fileState {
+	newFiles := make(map[string]fileState, len(files))
+	for fileName := range files {
+		info, err := os.Stat(fileName)
+		if err != nil {
+			continue
+		}
+		newFiles[fileName] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return newFiles
+}
+
+// changedFiles returns files which were added, removed or modified
+func changedFiles(before map[string]fileState, after map[string]fileState) []string {
+	var changed []string
+	for fileName, state := range after {
+		if previousState, isFound := before[fileName]; !isFound || previousState != state {
+			changed = append(changed, fileName)
+		}
+	}
+	for fileName := range before {
+		if _, isFound := after[fileName]; !isFound {
+			changed = append(changed, fileName)
+		}
+	}
+	return changed
+}
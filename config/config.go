@@ -2,48 +2,99 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"gopkg.in/yaml.v3"
 	"os"
+	"path/filepath"
+	"recodegen/errs"
+	"strings"
 )
 
-func ReadConfigFromFile(fileName string) CodegenConfig {
-	configData, _ := os.ReadFile(fileName)
+// DefaultFileNames are config files looked up in the working directory when no config file is given,
+// the first existing one is used
+var DefaultFileNames = []string{"recodegen.json", "codegen.yml", "codegen.yaml", "codegen.json"}
 
+// FindConfigFile returns the first of DefaultFileNames which exists
+func FindConfigFile() (string, error) {
+	for _, fileName := range DefaultFileNames {
+		if _, err := os.Stat(fileName); err == nil {
+			return fileName, nil
+		}
+	}
+	return "", &errs.ConfigError{
+		File: strings.Join(DefaultFileNames, ", "),
+		Err:  errors.New("no config file found, use -config to set one"),
+	}
+}
+
+// ReadConfigFromFile reads JSON or YAML config, format is detected by file extension
+func ReadConfigFromFile(fileName string) (CodegenConfig, error) {
 	var codegenSchema CodegenConfig
 
-	err := json.Unmarshal(configData, &codegenSchema)
+	configData, err := os.ReadFile(fileName)
+	if err != nil {
+		return codegenSchema, &errs.ConfigError{File: fileName, Err: err}
+	}
+
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if extension == ".yml" || extension == ".yaml" {
+		configData, err = yamlToJSON(configData)
+		if err != nil {
+			return codegenSchema, &errs.ConfigError{File: fileName, Err: fmt.Errorf("unable to parse YAML: %w", err)}
+		}
+	}
+
+	err = json.Unmarshal(configData, &codegenSchema)
 	if err != nil {
-		fmt.Println(err)
-		fmt.Println("Unable to parse JSON in config file: " + fileName)
-		os.Exit(1)
+		return codegenSchema, &errs.ConfigError{File: fileName, Err: fmt.Errorf("unable to parse JSON: %w", err)}
 	}
 
-	return codegenSchema
+	return codegenSchema, nil
+}
+
+// yamlToJSON converts YAML to JSON, so YAML config is unmarshalled the same way as JSON one
+func yamlToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+	return json.Marshal(value)
 }
 
 // Structs schema.codegen.json
 type CodegenConfig struct {
-	Overwrite bool               `json:"overwrite"`
-	Schema    string             `json:"schema"`
-	Generates CodegenSchemaEntry `json:"generates"`
+	// Overwrite allows to replace existing output files, true if not set
+	Overwrite *bool               `json:"overwrite,omitempty"`
+	Schema    Sources             `json:"schema"`
+	Config    CodegenPluginConfig `json:"config"`
+	Generates CodegenSchemaEntry  `json:"generates"`
 }
 
-func (schema CodegenConfig) JSON() string {
-	b, err := json.Marshal(schema)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+// ShouldOverwrite reports if an existing output file can be replaced, per output value takes precedence
+func (schema CodegenConfig) ShouldOverwrite(outputFileName string) bool {
+	if overwrite := schema.Generates[outputFileName].Overwrite; overwrite != nil {
+		return *overwrite
 	}
-	return fmt.Sprint(string(b))
+	if schema.Overwrite != nil {
+		return *schema.Overwrite
+	}
+	return true
 }
 
-func (schema CodegenConfig) JSONByte() []byte {
-	b, err := json.Marshal(schema)
+func (schema CodegenConfig) JSON() (string, error) {
+	b, err := schema.JSONByte()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return "", err
 	}
-	return b
+	return string(b), nil
+}
+
+func (schema CodegenConfig) JSONByte() ([]byte, error) {
+	return json.Marshal(schema)
 }
 
 type CodegenSchemaEntry map[string]CodegenSchemaEntryConfig
@@ -54,5 +105,79 @@ type CodegenSchemaEntryConfig struct {
 	Preset       string              `json:"preset"`
 	PresetConfig CodegenPresetConfig `json:"presetConfig"`
 	Plugins      []string            `json:"plugins"`
-	Documents    []string            `json:"documents"`
+	Documents    Sources             `json:"documents"`
+	Config       CodegenPluginConfig `json:"config"`
+	// Overwrite overrides global Overwrite for this output
+	Overwrite *bool `json:"overwrite,omitempty"`
+}
+
+// CodegenPluginConfig is a "config" section which can be set globally or per output file
+type CodegenPluginConfig struct {
+	Scalars map[string]ScalarType `json:"scalars"`
+	// StrictScalars fails generation if a custom scalar has no mapping in Scalars
+	StrictScalars *bool `json:"strictScalars,omitempty"`
+	// DefaultScalarType is used for custom scalars without mapping, "any" if not set
+	DefaultScalarType string `json:"defaultScalarType"`
+	// Minify writes introspection JSON without indentation
+	Minify *bool `json:"minify,omitempty"`
+	// Descriptions includes descriptions into introspection JSON, true if not set
+	Descriptions *bool `json:"descriptions,omitempty"`
+}
+
+// Merge returns config with values from other config taking precedence,
+// used to apply per output config on top of the global one
+func (pluginConfig CodegenPluginConfig) Merge(other CodegenPluginConfig) CodegenPluginConfig {
+	merged := CodegenPluginConfig{
+		Scalars:           map[string]ScalarType{},
+		StrictScalars:     pluginConfig.StrictScalars,
+		DefaultScalarType: pluginConfig.DefaultScalarType,
+		Minify:            pluginConfig.Minify,
+		Descriptions:      pluginConfig.Descriptions,
+	}
+	if other.DefaultScalarType != "" {
+		merged.DefaultScalarType = other.DefaultScalarType
+	}
+	if other.StrictScalars != nil {
+		merged.StrictScalars = other.StrictScalars
+	}
+	if other.Minify != nil {
+		merged.Minify = other.Minify
+	}
+	if other.Descriptions != nil {
+		merged.Descriptions = other.Descriptions
+	}
+	for name, scalarType := range pluginConfig.Scalars {
+		merged.Scalars[name] = scalarType
+	}
+	for name, scalarType := range other.Scalars {
+		merged.Scalars[name] = scalarType
+	}
+	return merged
+}
+
+// ScalarType is a TypeScript type of a scalar, it can be set as a string "string"
+// or as an object { "input": "string", "output": "Date" } when input and output types differ
+type ScalarType struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+func (scalarType *ScalarType) UnmarshalJSON(data []byte) error {
+	var typeName string
+	if err := json.Unmarshal(data, &typeName); err == nil {
+		scalarType.Input = typeName
+		scalarType.Output = typeName
+		return nil
+	}
+
+	type scalarTypeObject ScalarType
+	var object scalarTypeObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("scalar type should be a string or an object with input and output: %w", err)
+	}
+	if object.Input == "" || object.Output == "" {
+		return fmt.Errorf("scalar type should have both input and output set")
+	}
+	*scalarType = ScalarType(object)
+	return nil
 }
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSourcesUnmarshalJSON(t *testing.T) {
+	headers := SourceOptions{Headers: map[string]string{"x-token": "${TOKEN}"}}
+	tests := []struct {
+		name    string
+		json    string
+		want    Sources
+		wantErr bool
+	}{
+		{
+			name: "string",
+			json: `"schema.graphql"`,
+			want: Sources{{Path: "schema.graphql"}},
+		},
+		{
+			name: "array",
+			json: `["a.graphql", "b/**/*.ts"]`,
+			want: Sources{{Path: "a.graphql"}, {Path: "b/**/*.ts"}},
+		},
+		{
+			name: "object with sorted keys",
+			json: `{"https://example.com/graphql": {"headers": {"x-token": "${TOKEN}"}}, "a.graphql": {}}`,
+			want: Sources{{Path: "a.graphql"}, {Path: "https://example.com/graphql", Options: headers}},
+		},
+		{
+			name: "mixed array",
+			json: `["a.graphql", {"https://example.com/graphql": {"headers": {"x-token": "${TOKEN}"}}}, "b.graphql"]`,
+			want: Sources{{Path: "a.graphql"}, {Path: "https://example.com/graphql", Options: headers}, {Path: "b.graphql"}},
+		},
+		{
+			name: "null",
+			json: `null`,
+			want: Sources{},
+		},
+		{
+			name: "empty array",
+			json: `[]`,
+			want: Sources{},
+		},
+		{
+			name:    "number",
+			json:    `1`,
+			wantErr: true,
+		},
+		{
+			name:    "number in array",
+			json:    `["a.graphql", 1]`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sources Sources
+			err := json.Unmarshal([]byte(test.json), &sources)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", sources)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sources, test.want) {
+				t.Fatalf("got %+v, want %+v", sources, test.want)
+			}
+		})
+	}
+}
+
+func TestSourcesMarshalJSON(t *testing.T) {
+	sources := Sources{
+		{Path: "a.graphql"},
+		{Path: "https://example.com/graphql", Options: SourceOptions{Headers: map[string]string{"x-token": "${TOKEN}"}}},
+	}
+	data, err := json.Marshal(sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `["a.graphql",{"https://example.com/graphql":{"headers":{"x-token":"${TOKEN}"}}}]`
+	if string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+
+	var unmarshalled Sources
+	if err := json.Unmarshal(data, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshalled, sources) {
+		t.Fatalf("got %+v after round trip, want %+v", unmarshalled, sources)
+	}
+}
+
+func TestScalarTypeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    ScalarType
+		wantErr bool
+	}{
+		{name: "string", json: `"string"`, want: ScalarType{Input: "string", Output: "string"}},
+		{name: "object", json: `{"input": "string", "output": "Date"}`, want: ScalarType{Input: "string", Output: "Date"}},
+		{name: "object without output", json: `{"input": "string"}`, wantErr: true},
+		{name: "number", json: `1`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var scalarType ScalarType
+			err := json.Unmarshal([]byte(test.json), &scalarType)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", scalarType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if scalarType != test.want {
+				t.Fatalf("got %+v, want %+v", scalarType, test.want)
+			}
+		})
+	}
+}
+
+func TestPluginConfigMerge(t *testing.T) {
+	isTrue, isFalse := true, false
+	global := CodegenPluginConfig{
+		Scalars: map[string]ScalarType{
+			"uuid": {Input: "string", Output: "string"},
+			"date": {Input: "string", Output: "string"},
+		},
+		StrictScalars:     &isTrue,
+		DefaultScalarType: "unknown",
+		Minify:            &isTrue,
+	}
+
+	t.Run("empty output config keeps global values", func(t *testing.T) {
+		merged := global.Merge(CodegenPluginConfig{})
+		if !reflect.DeepEqual(merged, global) {
+			t.Fatalf("got %+v, want %+v", merged, global)
+		}
+	})
+
+	t.Run("output values take precedence", func(t *testing.T) {
+		merged := global.Merge(CodegenPluginConfig{
+			Scalars:           map[string]ScalarType{"date": {Input: "string", Output: "Date"}},
+			StrictScalars:     &isFalse,
+			DefaultScalarType: "any",
+			Descriptions:      &isFalse,
+		})
+		want := CodegenPluginConfig{
+			Scalars: map[string]ScalarType{
+				"uuid": {Input: "string", Output: "string"},
+				"date": {Input: "string", Output: "Date"},
+			},
+			StrictScalars:     &isFalse,
+			DefaultScalarType: "any",
+			Minify:            &isTrue,
+			Descriptions:      &isFalse,
+		}
+		if !reflect.DeepEqual(merged, want) {
+			t.Fatalf("got %+v, want %+v", merged, want)
+		}
+	})
+
+	t.Run("global config isn't modified", func(t *testing.T) {
+		global.Merge(CodegenPluginConfig{Scalars: map[string]ScalarType{"json": {Input: "any", Output: "any"}}})
+		if _, isSet := global.Scalars["json"]; isSet {
+			t.Fatal("output scalar leaked into global config")
+		}
+	})
+}
+
+func TestReadConfigFromFileYAMLAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	jsonFileName := filepath.Join(dir, "codegen.json")
+	yamlFileName := filepath.Join(dir, "codegen.yml")
+	writeFile(t, jsonFileName, `{
+  "overwrite": false,
+  "schema": ["schema/*.graphql", {"https://example.com/graphql": {"headers": {"x-token": "${TOKEN}"}}}],
+  "config": {"scalars": {"uuid": "string", "timestamptz": {"input": "string", "output": "Date"}}, "strictScalars": true},
+  "generates": {
+    "generated/schema.ts": {"plugins": ["typescript"]},
+    "generated/operations.ts": {
+      "preset": "import-types",
+      "presetConfig": {"typesPath": "./schema"},
+      "documents": "src/**/*.ts",
+      "plugins": ["typescript-operations"],
+      "config": {"strictScalars": false}
+    }
+  }
+}`)
+	writeFile(t, yamlFileName, `overwrite: false
+schema:
+  - schema/*.graphql
+  - https://example.com/graphql:
+      headers:
+        x-token: ${TOKEN}
+config:
+  scalars:
+    uuid: string
+    timestamptz:
+      input: string
+      output: Date
+  strictScalars: true
+generates:
+  generated/schema.ts:
+    plugins:
+      - typescript
+  generated/operations.ts:
+    preset: import-types
+    presetConfig:
+      typesPath: ./schema
+    documents: src/**/*.ts
+    plugins:
+      - typescript-operations
+    config:
+      strictScalars: false
+`)
+
+	jsonConfig, err := ReadConfigFromFile(jsonFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlConfig, err := ReadConfigFromFile(yamlFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(jsonConfig, yamlConfig) {
+		t.Fatalf("YAML config %+v differs from JSON one %+v", yamlConfig, jsonConfig)
+	}
+	if jsonConfig.ShouldOverwrite("generated/schema.ts") {
+		t.Fatal("expected overwrite to be off")
+	}
+	if len(jsonConfig.Schema) != 2 || jsonConfig.Schema[1].Options.Headers["x-token"] != "${TOKEN}" {
+		t.Fatalf("unexpected schema sources %+v", jsonConfig.Schema)
+	}
+}
+
+func TestReadConfigFromFileInvalidYAML(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "codegen.yaml")
+	writeFile(t, fileName, "schema: [a.graphql\n")
+	if _, err := ReadConfigFromFile(fileName); err == nil {
+		t.Fatal("expected error for invalid YAML")
+	}
+}
+
+func writeFile(t *testing.T, fileName string, content string) {
+	t.Helper()
+	if err := os.WriteFile(fileName, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Source is a location of schema or documents, a file path, a glob or a URL, with its options
+type Source struct {
+	Path    string
+	Options SourceOptions
+}
+
+// SourceOptions are options set with the object form { "path": { options } }
+type SourceOptions struct {
+	// Headers are sent with requests when the source is a URL
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func (options SourceOptions) isEmpty() bool {
+	return len(options.Headers) == 0
+}
+
+// Sources is a list of schema or documents sources, same as in @graphql-codegen config it can be set as
+// a string "schema.graphql", an array ["a.graphql", "b.graphql"], an object { "schema.graphql": { options } }
+// or an array mixing strings and objects
+type Sources []Source
+
+// Paths returns paths of all sources
+func (sources Sources) Paths() []string {
+	paths := make([]string, 0, len(sources))
+	for _, source := range sources {
+		paths = append(paths, source.Path)
+	}
+	return paths
+}
+
+func (sources *Sources) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		// not an array, a single string or object
+		items = []json.RawMessage{data}
+	}
+
+	result := Sources{}
+	for _, item := range items {
+		itemSources, err := unmarshalSource(item)
+		if err != nil {
+			return err
+		}
+		result = append(result, itemSources...)
+	}
+	*sources = result
+	return nil
+}
+
+// unmarshalSource unmarshals a string or an object with paths as keys, object keys are sorted
+// so sources are always in the same order
+func unmarshalSource(data []byte) (Sources, error) {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		return Sources{{Path: path}}, nil
+	}
+
+	var object map[string]SourceOptions
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("source should be a string, an array or an object with paths as keys: %w", err)
+	}
+	paths := make([]string, 0, len(object))
+	for path := range object {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var sources Sources
+	for _, path := range paths {
+		sources = append(sources, Source{Path: path, Options: object[path]})
+	}
+	return sources, nil
+}
+
+// MarshalJSON writes sources as an array, sources with options are written in the object form
+func (sources Sources) MarshalJSON() ([]byte, error) {
+	items := make([]interface{}, 0, len(sources))
+	for _, source := range sources {
+		if source.Options.isEmpty() {
+			items = append(items, source.Path)
+			continue
+		}
+		items = append(items, map[string]SourceOptions{source.Path: source.Options})
+	}
+	return json.Marshal(items)
+}
@@ -0,0 +1,249 @@
+// Package diff produces unified diffs of text files, it's used to show how generated files differ from existing ones
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// number of unchanged lines shown around changes
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op is a single line of the edit script, oldLine and newLine are 0-based line numbers
+// in old and new text before this line
+type op struct {
+	kind    opKind
+	line    string
+	oldLine int
+	newLine int
+}
+
+// Unified returns unified diff between old and new text, empty string is returned if they are equal
+func Unified(oldName string, newName string, oldText string, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := editScript(splitLines(oldText), splitLines(newText))
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range hunks(ops) {
+		writeHunk(&output, hunk)
+	}
+	return output.String()
+}
+
+// splitLines splits text into lines keeping line breaks, so a missing line break at the end is a change too
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript returns the shortest edit script which turns a into b, using linear space variant of Myers' algorithm
+func editScript(a []string, b []string) []op {
+	ops := make([]op, 0, len(a)+len(b))
+	return diffRange(ops, a, b, 0, 0)
+}
+
+// diffRange appends operations which turn a into b to ops, aStart and bStart are line numbers of the first
+// lines of a and b in the whole text
+func diffRange(ops []op, a []string, b []string, aStart int, bStart int) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, op{kind: opEqual, line: a[prefix], oldLine: aStart + prefix, newLine: bStart + prefix})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+	aStart, bStart = aStart+prefix, bStart+prefix
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-suffix-1] == b[len(b)-suffix-1] {
+		suffix++
+	}
+	aSuffix := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	x, y := -1, -1
+	if len(a) > 0 && len(b) > 0 {
+		x, y = middle(a, b)
+	}
+	if x <= 0 && y <= 0 || x >= len(a) && y >= len(b) {
+		// one of the sides is empty or nothing is shared, so everything old is deleted and everything new inserted
+		for i, line := range a {
+			ops = append(ops, op{kind: opDelete, line: line, oldLine: aStart + i, newLine: bStart})
+		}
+		for i, line := range b {
+			ops = append(ops, op{kind: opInsert, line: line, oldLine: aStart + len(a), newLine: bStart + i})
+		}
+	} else {
+		ops = diffRange(ops, a[:x], b[:y], aStart, bStart)
+		ops = diffRange(ops, a[x:], b[y:], aStart+x, bStart+y)
+	}
+
+	aStart, bStart = aStart+len(a), bStart+len(b)
+	for i, line := range aSuffix {
+		ops = append(ops, op{kind: opEqual, line: line, oldLine: aStart + i, newLine: bStart + i})
+	}
+	return ops
+}
+
+// middle finds a point on the shortest edit path which splits it in two halves, searching from both ends
+// at the same time and keeping only the furthest reaching x of each diagonal, so memory is linear.
+// -1, -1 is returned if a and b don't share any line
+func middle(a []string, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+	delta := n - m
+	// paths overlap on the forward pass if delta is odd, otherwise on the backward one
+	odd := delta%2 != 0
+	// diagonals which went past the edges are skipped
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			x := next(forward, offset+k, k, d)
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if x > n {
+				forwardEnd += 2
+			} else if y > m {
+				forwardStart += 2
+			} else if odd {
+				i := offset + delta - k
+				if i >= 0 && i < size && backward[i] != -1 && x >= n-backward[i] {
+					return x, y
+				}
+			}
+		}
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			x := next(backward, offset+k, k, d)
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if x > n {
+				backwardEnd += 2
+			} else if y > m {
+				backwardStart += 2
+			} else if !odd {
+				i := offset + delta - k
+				if i >= 0 && i < size && forward[i] != -1 {
+					forwardX := forward[i]
+					forwardY := forwardX - (i - offset)
+					if forwardX >= n-x {
+						return forwardX, forwardY
+					}
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+// next returns x where the path on diagonal k starts on step d, continuing the furthest of neighbor diagonals
+func next(v []int, i int, k int, d int) int {
+	if k == -d || (k != d && v[i-1] < v[i+1]) {
+		return v[i+1]
+	}
+	return v[i-1] + 1
+}
+
+// hunks groups changed lines with their context, changes which are close to each other share a hunk
+func hunks(ops []op) [][]op {
+	var result [][]op
+	start, end := -1, -1
+	for i, operation := range ops {
+		if operation.kind == opEqual {
+			continue
+		}
+		if start >= 0 && i-contextLines <= end+contextLines {
+			end = i
+			continue
+		}
+		if start >= 0 {
+			result = append(result, ops[start:min(end+contextLines+1, len(ops))])
+		}
+		start = max(i-contextLines, 0)
+		end = i
+	}
+	if start >= 0 {
+		result = append(result, ops[start:min(end+contextLines+1, len(ops))])
+	}
+	return result
+}
+
+func writeHunk(output *strings.Builder, hunk []op) {
+	oldCount, newCount := 0, 0
+	for _, operation := range hunk {
+		if operation.kind != opInsert {
+			oldCount++
+		}
+		if operation.kind != opDelete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(output, "@@ -%s +%s @@\n",
+		hunkRange(hunk[0].oldLine, oldCount), hunkRange(hunk[0].newLine, newCount))
+	for _, operation := range hunk {
+		output.WriteByte(byte(operation.kind))
+		output.WriteString(operation.line)
+		if !strings.HasSuffix(operation.line, "\n") {
+			output.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a range of lines, empty range refers to the line before it as diff tools expect
+func hunkRange(line int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line+1)
+	}
+	return fmt.Sprintf("%d,%d", line+1, count)
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
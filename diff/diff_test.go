@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// apply applies unified diff produced by Unified to old text and returns the new one
+func apply(t *testing.T, oldText string, patch string) string {
+	t.Helper()
+	oldLines := splitLines(oldText)
+	lines := strings.SplitAfter(patch, "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "--- ") || !strings.HasPrefix(lines[1], "+++ ") {
+		t.Fatalf("patch has no header:\n%s", patch)
+	}
+	var result []string
+	position := 0
+	for i := 2; i < len(lines) && lines[i] != ""; i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "@@ ") {
+			oldRange := strings.Fields(line)[1][1:]
+			start, _, _ := strings.Cut(oldRange, ",")
+			oldStart, err := strconv.Atoi(start)
+			if err != nil {
+				t.Fatalf("bad hunk header %q", line)
+			}
+			// empty range refers to the line before it
+			if strings.HasSuffix(oldRange, ",0") {
+				oldStart++
+			}
+			for position < oldStart-1 {
+				result = append(result, oldLines[position])
+				position++
+			}
+			continue
+		}
+		text := line[1:]
+		if i+1 < len(lines) && lines[i+1] == "\\ No newline at end of file\n" {
+			text = strings.TrimSuffix(text, "\n")
+			i++
+		}
+		switch line[0] {
+		case ' ', '-':
+			if position >= len(oldLines) || oldLines[position] != text {
+				t.Fatalf("line %d of old text doesn't match %q in patch:\n%s", position+1, text, patch)
+			}
+			if line[0] == ' ' {
+				result = append(result, text)
+			}
+			position++
+		case '+':
+			result = append(result, text)
+		default:
+			t.Fatalf("unexpected patch line %q", line)
+		}
+	}
+	result = append(result, oldLines[position:]...)
+	return strings.Join(result, "")
+}
+
+func TestUnifiedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+	}{
+		{"equal", "a\nb\n", "a\nb\n"},
+		{"missing old file", "", "a\nb\nc\n"},
+		{"emptied file", "a\nb\nc\n", ""},
+		{"line changed", "a\nb\nc\n", "a\nx\nc\n"},
+		{"line inserted at the start", "b\nc\n", "a\nb\nc\n"},
+		{"line appended", "a\nb\n", "a\nb\nc\n"},
+		{"line removed in the middle", "a\nb\nc\n", "a\nc\n"},
+		{"nothing shared", "a\nb\n", "c\nd\ne\n"},
+		{"missing line break at the end", "a\nb\n", "a\nb"},
+		{"line break added at the end", "a\nb", "a\nb\n"},
+		{"distant changes", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n13\n"},
+		{"repeated lines", "a\na\nb\na\na\n", "a\nb\na\nb\na\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch := Unified("old", "new", test.oldText, test.newText)
+			if test.oldText == test.newText {
+				if patch != "" {
+					t.Fatalf("expected no diff for equal texts, got:\n%s", patch)
+				}
+				return
+			}
+			if got := apply(t, test.oldText, patch); got != test.newText {
+				t.Fatalf("patch doesn't turn old text into new one, got %q, want %q, patch:\n%s", got, test.newText, patch)
+			}
+		})
+	}
+}
+
+func TestUnifiedRandomRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		var text strings.Builder
+		for i := random.Intn(30); i > 0; i-- {
+			fmt.Fprintf(&text, "%c\n", 'a'+random.Intn(4))
+		}
+		return text.String()
+	}
+	for i := 0; i < 500; i++ {
+		oldText, newText := randomText(), randomText()
+		patch := Unified("old", "new", oldText, newText)
+		if got := apply(t, oldText, patch); got != newText {
+			t.Fatalf("patch doesn't turn %q into %q, got %q, patch:\n%s", oldText, newText, got, patch)
+		}
+	}
+}
+
+func TestUnifiedShortestScript(t *testing.T) {
+	oldText := "a\nb\nc\na\nb\nb\na\n"
+	newText := "c\nb\na\nb\na\nc\n"
+	changes := 0
+	for _, operation := range editScript(splitLines(oldText), splitLines(newText)) {
+		if operation.kind != opEqual {
+			changes++
+		}
+	}
+	// the example from Myers' paper has an edit script of 5 operations
+	if changes != 5 {
+		t.Fatalf("expected 5 changes, got %d", changes)
+	}
+}
+
+func TestUnifiedLargeMissingFile(t *testing.T) {
+	var text strings.Builder
+	for i := 0; i < 50000; i++ {
+		fmt.Fprintf(&text, "export type Type%d = { id: string };\n", i)
+	}
+	patch := Unified("old", "new", "", text.String())
+	if !strings.HasPrefix(patch, "--- old\n+++ new\n@@ -0,0 +1,50000 @@\n") {
+		t.Fatalf("expected a single all-insert hunk, got:\n%s", patch[:200])
+	}
+}
+
+func TestUnifiedLargeRewrite(t *testing.T) {
+	var oldText, newText strings.Builder
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&oldText, "old %d\n", i)
+		fmt.Fprintf(&newText, "new %d\n", i)
+		if i%7 == 0 {
+			fmt.Fprintf(&oldText, "shared %d\n", i)
+			fmt.Fprintf(&newText, "shared %d\n", i)
+		}
+	}
+	patch := Unified("old", "new", oldText.String(), newText.String())
+	if got := apply(t, oldText.String(), patch); got != newText.String() {
+		t.Fatal("patch doesn't turn old text into new one")
+	}
+}
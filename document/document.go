@@ -0,0 +1,186 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
+	"os"
+	"path/filepath"
+	"recodegen/errs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Document is a GraphQL document extracted from a source file
+type Document struct {
+	File string `json:"file"`
+	// Line and Column where Content starts in the File, both are 1-based
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Content string `json:"content"`
+	// Imports are files referenced by #import "./fragment.graphql" comments
+	Imports []string `json:"imports,omitempty"`
+}
+
+// Set holds documents extracted from all scanned files.
+// It's loaded once and then shared between outputs, it's never modified after loading
+type Set struct {
+	files map[string][]*Document
+	// sorted names of loaded files
+	fileNames []string
+	// fragment name => document where it's defined
+	fragments map[string]*Document
+}
+
+// LoadSet extracts documents from all files matching any of the given glob patterns
+func LoadSet(patterns []string) (*Set, error) {
+	set := &Set{
+		files:     make(map[string][]*Document),
+		fragments: make(map[string]*Document),
+	}
+	for _, fileName := range FindFiles(patterns) {
+		if err := set.loadFile(fileName); err != nil {
+			return nil, err
+		}
+	}
+	for fileName := range set.files {
+		set.fileNames = append(set.fileNames, fileName)
+	}
+	sort.Strings(set.fileNames)
+	return set, nil
+}
+
+// loadFile extracts documents from the file and files it imports, and indexes fragments defined in them
+func (set *Set) loadFile(fileName string) error {
+	if _, isLoaded := set.files[fileName]; isLoaded {
+		return nil
+	}
+	documents, err := findOperationInFile(fileName)
+	if err != nil {
+		return err
+	}
+	set.files[fileName] = documents
+	for _, document := range documents {
+		// documents with syntax errors are reported when used by an output
+		documentAst, err := document.parse()
+		if err == nil {
+			for _, fragment := range documentAst.Fragments {
+				if _, isDefined := set.fragments[fragment.Name]; !isDefined {
+					set.fragments[fragment.Name] = document
+				}
+			}
+		}
+		for _, importFileName := range document.Imports {
+			if err := set.loadFile(importFileName); err != nil {
+				// a file which can't be read is reported with the file importing it
+				var ioErr *errs.IOError
+				if errors.As(err, &ioErr) && ioErr.Path == importFileName {
+					return &errs.IOError{
+						Path: importFileName,
+						Err:  fmt.Errorf("%s: #import %q: %w", document.File, importFileName, ioErr.Err),
+					}
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Select returns documents from loaded files matching glob patterns in the order of file names,
+// nothing is read from disk, so all outputs share the same files
+func (set *Set) Select(patterns []string) []*Document {
+	var output []*Document
+	for _, fileName := range set.fileNames {
+		if Match(patterns, fileName) {
+			output = append(output, set.files[fileName]...)
+		}
+	}
+	return output
+}
+
+// HasFragments checks if documents of the loaded file define any fragments
+func (set *Set) HasFragments(fileName string) bool {
+	for _, document := range set.files[fileName] {
+		documentAst, err := document.parse()
+		if err == nil && len(documentAst.Fragments) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Match checks if file name matches any of the glob patterns
+func Match(patterns []string, fileName string) bool {
+	for _, pattern := range patterns {
+		if isMatch, _ := doublestar.Match(pattern, fileName); isMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// FindFiles returns unique file names matching glob patterns in the order of patterns
+func FindFiles(patterns []string) []string {
+	var output []string
+	seen := make(map[string]bool)
+	fsys := os.DirFS(".")
+	for _, pattern := range patterns {
+		matches, _ := doublestar.Glob(fsys, pattern)
+		sort.Strings(matches)
+		for _, fileName := range matches {
+			if seen[fileName] {
+				continue
+			}
+			seen[fileName] = true
+			output = append(output, fileName)
+		}
+	}
+	return output
+}
+
+// findOperationInFile extracts documents from a file depending on its extension,
+// GraphQL files are documents as is, documents in any other files are searched in gql`...` strings
+func findOperationInFile(fileName string) ([]*Document, error) {
+	fileContent, err := ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".graphql", ".graphqls", ".gql":
+		return []*Document{{
+			File:    fileName,
+			Line:    1,
+			Column:  1,
+			Content: fileContent,
+			Imports: findImports(fileName, fileContent),
+		}}, nil
+	}
+	return Pluck(fileName, fileContent), nil
+}
+
+// matches #import "./fragment.graphql" comment
+var importRegexp = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*import[ \t]+["']([^"']+)["']`)
+
+// findImports returns file names imported with #import comments, relative to the importing file
+func findImports(fileName string, fileContent string) []string {
+	var imports []string
+	for _, match := range importRegexp.FindAllStringSubmatch(fileContent, -1) {
+		importFileName := match[1]
+		if !filepath.IsAbs(importFileName) {
+			importFileName = filepath.Join(filepath.Dir(fileName), importFileName)
+		}
+		imports = append(imports, importFileName)
+	}
+	return imports
+}
+
+// ReadFile returns content of a document or schema file, IOError is returned if it can't be read
+func ReadFile(fileName string) (string, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", &errs.IOError{Path: fileName, Err: err}
+	}
+	return string(content), nil
+}
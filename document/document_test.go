@@ -0,0 +1,101 @@
+package document
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"recodegen/errs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// inTempDir writes files into a temporary directory and makes it the working directory for the test,
+// because documents are loaded by globs relative to the working directory
+func inTempDir(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for fileName, content := range files {
+		writeTestFile(t, filepath.Join(dir, fileName), content)
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(workingDir)
+	})
+}
+
+func writeTestFile(t *testing.T, fileName string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func documentFiles(documents []*Document) []string {
+	var fileNames []string
+	for _, document := range documents {
+		fileNames = append(fileNames, document.File)
+	}
+	return fileNames
+}
+
+func TestSetSelect(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"admin/users.graphql":  "query Users { users { id } }",
+		"client/me.ts":         "const q = gql`query Me { me { id } }`;",
+		"client/posts.graphql": "query Posts { posts { id } }",
+	})
+	set, err := LoadSet([]string{"admin/*.graphql", "client/**/*.{ts,graphql}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// files which appear after loading aren't selected
+	writeTestFile(t, "client/new.graphql", "query New { new }")
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"single pattern", []string{"admin/*.graphql"}, []string{"admin/users.graphql"}},
+		{"files are sorted", []string{"client/**/*.graphql", "client/*.ts"}, []string{"client/me.ts", "client/posts.graphql"}},
+		{"file matching many patterns is selected once", []string{"client/*", "client/*.ts"}, []string{"client/me.ts", "client/posts.graphql"}},
+		{"nothing matches", []string{"server/*.ts"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := documentFiles(set.Select(test.patterns)); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadSetMissingImport(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"gql/q.graphql":          "#import \"./frag/user.graphql\"\nquery Q { me { ...User } }",
+		"gql/frag/user.graphql":  "#import \"./missing.graphql\"\nfragment User on User { id }",
+		"gql/other/post.graphql": "query Post { post { id } }",
+	})
+	_, err := LoadSet([]string{"gql/*.graphql"})
+	var ioErr *errs.IOError
+	if !errors.As(err, &ioErr) {
+		t.Fatalf("expected IOError, got %v", err)
+	}
+	want := `gql/frag/user.graphql: #import "gql/frag/missing.graphql": open gql/frag/missing.graphql: `
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("expected error starting with %q, got %q", want, err)
+	}
+	if ioErr.Path != "gql/frag/missing.graphql" {
+		t.Fatalf("expected path of the missing file, got %s", ioErr.Path)
+	}
+}
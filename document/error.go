@@ -0,0 +1,79 @@
+package document
+
+import (
+	"fmt"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"sort"
+	"strings"
+)
+
+// Error is a syntax or validation error in a document, its position points to the file the document was extracted from
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error formats error like compilers do: file:line:column: message
+func (err *Error) Error() string {
+	if err.File == "" {
+		return err.Message
+	}
+	if err.Line == 0 {
+		return fmt.Sprintf("%s: %s", err.File, err.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", err.File, err.Line, err.Column, err.Message)
+}
+
+// ErrorList is a list of all errors found in documents, sorted by file and position
+type ErrorList []*Error
+
+func (errs ErrorList) Error() string {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (errs ErrorList) sort() {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].File != errs[j].File {
+			return errs[i].File < errs[j].File
+		}
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+}
+
+// newError maps position of a parser or validator error in a document to the position in the source file,
+// sources are documents by the source name they were parsed with
+func newError(err error, sources map[string]*Document) *Error {
+	gqlErr, isGqlErr := err.(*gqlerror.Error)
+	if !isGqlErr {
+		return &Error{Message: err.Error()}
+	}
+
+	output := &Error{Message: gqlErr.Message}
+	sourceName, _ := gqlErr.Extensions["file"].(string)
+	document, isFound := sources[sourceName]
+	if !isFound {
+		output.File = sourceName
+		return output
+	}
+
+	output.File = document.File
+	if len(gqlErr.Locations) > 0 {
+		location := gqlErr.Locations[0]
+		output.Line = document.Line + location.Line - 1
+		output.Column = location.Column
+		// only the first line of the document is shifted, e.g. gql`query {` starts in the middle of a line
+		if location.Line == 1 {
+			output.Column += document.Column - 1
+		}
+	}
+	return output
+}
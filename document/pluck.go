@@ -0,0 +1,399 @@
+package document
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// identifiers which mark a template literal or a call argument as GraphQL document
+var graphqlTags = map[string]bool{
+	"gql":     true,
+	"graphql": true,
+}
+
+// keywords after which "/" starts a regular expression rather than a division
+var regexpKeywords = map[string]bool{
+	"return":     true,
+	"typeof":     true,
+	"instanceof": true,
+	"in":         true,
+	"of":         true,
+	"new":        true,
+	"delete":     true,
+	"void":       true,
+	"throw":      true,
+	"case":       true,
+	"do":         true,
+	"else":       true,
+	"yield":      true,
+	"await":      true,
+}
+
+// keywords followed by a parenthesized condition, "/" after the closing ")" starts a regular expression
+var conditionKeywords = map[string]bool{
+	"if":    true,
+	"while": true,
+	"for":   true,
+	"with":  true,
+}
+
+// extensions of files which can't have JSX, "<" in them can be a type assertion like <string>value
+var noJSXExtensions = map[string]bool{
+	".ts":  true,
+	".mts": true,
+	".cts": true,
+}
+
+// kind of the last significant token, it decides whether the next string is a GraphQL document
+// and whether "/" is a division or a start of a regular expression
+type tokenKind int
+
+const (
+	tokenOperator tokenKind = iota
+	tokenOperand
+	tokenTag     // gql or graphql identifier
+	tokenTagCall // gql( or graphql(
+	tokenComment // /* GraphQL */ comment
+	tokenKeyword // if, while, for or with followed by a condition in parentheses
+)
+
+// plucker is a minimal TypeScript/JavaScript tokenizer which finds GraphQL documents in:
+//
+//	gql`...`, graphql`...`, gql(`...`), graphql("..."), /* GraphQL */ `...`
+//
+// comments, strings, regular expressions, JSX text and template literal expressions are skipped,
+// so backticks and quotes inside them don't break the search
+type plucker struct {
+	fileName string
+	src      string
+	pos      int
+	prev     tokenKind
+	// jsx is set if "<" can start JSX element
+	jsx       bool
+	documents []*Document
+}
+
+// Pluck returns GraphQL documents found in TypeScript/JavaScript source code,
+// JSX is recognized in all files except .ts ones
+func Pluck(fileName string, src string) []*Document {
+	p := &plucker{fileName: fileName, src: src, jsx: !noJSXExtensions[strings.ToLower(filepath.Ext(fileName))]}
+	p.scanCode(false)
+	return p.documents
+}
+
+// scanCode scans code until the end of source or, if inExpression is set,
+// until "}" which closes ${...} expression of a template literal or {...} expression of JSX
+func (p *plucker) scanCode(inExpression bool) {
+	depth := 0
+	// isCondition of every open "(", true if it's a condition of if, while, for or with
+	var parens []bool
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case c == '/' && p.peek(1) == '/':
+			p.skipLineComment()
+		case c == '/' && p.peek(1) == '*':
+			p.scanBlockComment()
+		case c == '/':
+			if p.prev == tokenOperand {
+				p.pos++
+				p.prev = tokenOperator
+			} else {
+				p.skipRegexp()
+				p.prev = tokenOperand
+			}
+		case c == '\'' || c == '"':
+			start := p.pos + 1
+			value := p.scanString(c)
+			if p.prev == tokenTagCall {
+				p.addDocument(start, value)
+			}
+			p.prev = tokenOperand
+		case c == '`':
+			isDocument := p.prev == tokenTag || p.prev == tokenTagCall || p.prev == tokenComment
+			start := p.pos + 1
+			value := p.scanTemplate()
+			if isDocument {
+				p.addDocument(start, value)
+			}
+			p.prev = tokenOperand
+		case isIdentPart(c):
+			ident := p.scanIdent()
+			if graphqlTags[ident] {
+				p.prev = tokenTag
+			} else if conditionKeywords[ident] {
+				p.prev = tokenKeyword
+			} else if regexpKeywords[ident] {
+				p.prev = tokenOperator
+			} else {
+				p.prev = tokenOperand
+			}
+		case c == '(':
+			p.pos++
+			parens = append(parens, p.prev == tokenKeyword)
+			if p.prev == tokenTag {
+				p.prev = tokenTagCall
+			} else {
+				p.prev = tokenOperator
+			}
+		case c == ')':
+			p.pos++
+			p.prev = tokenOperand
+			if len(parens) > 0 {
+				// a statement follows the condition, so "/" after it is a regular expression
+				if parens[len(parens)-1] {
+					p.prev = tokenOperator
+				}
+				parens = parens[:len(parens)-1]
+			}
+		case c == ']':
+			p.pos++
+			p.prev = tokenOperand
+		case c == '<' && p.jsx && p.prev == tokenOperator && (isIdentPart(p.peek(1)) || p.peek(1) == '>'):
+			p.scanJSXElement()
+			p.prev = tokenOperand
+		case c == '{':
+			p.pos++
+			depth++
+			p.prev = tokenOperator
+		case c == '}':
+			p.pos++
+			if inExpression && depth == 0 {
+				return
+			}
+			depth--
+			p.prev = tokenOperator
+		default:
+			p.pos++
+			p.prev = tokenOperator
+		}
+	}
+}
+
+// scanJSXElement skips JSX element like <div title="Don't">Don't {value}</div> including nested elements,
+// text of JSX isn't code, so quotes in it don't start strings. Expressions in {...} are scanned as code
+func (p *plucker) scanJSXElement() {
+	p.pos++ // opening <
+	// attributes
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '/' && p.peek(1) == '>':
+			p.pos += 2
+			return
+		case c == '>':
+			p.pos++
+			p.scanJSXChildren()
+			return
+		case c == '{':
+			p.pos++
+			p.prev = tokenOperator
+			p.scanCode(true)
+		case c == '"' || c == '\'':
+			// attribute strings have no escapes
+			end := strings.IndexByte(p.src[p.pos+1:], c)
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.pos += end + 2
+		default:
+			p.pos++
+		}
+	}
+}
+
+// scanJSXChildren skips text, expressions and nested elements until the closing tag
+func (p *plucker) scanJSXChildren() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '<' && p.peek(1) == '/':
+			end := strings.IndexByte(p.src[p.pos:], '>')
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.pos += end + 1
+			return
+		case c == '<':
+			p.scanJSXElement()
+		case c == '{':
+			p.pos++
+			p.prev = tokenOperator
+			p.scanCode(true)
+		default:
+			p.pos++
+		}
+	}
+}
+
+func (p *plucker) peek(offset int) byte {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *plucker) skipLineComment() {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		p.pos = len(p.src)
+		return
+	}
+	p.pos += end
+}
+
+// scanBlockComment skips /* */ comment, remembering it if it's a /* GraphQL */ marker
+func (p *plucker) scanBlockComment() {
+	end := strings.Index(p.src[p.pos+2:], "*/")
+	if end < 0 {
+		p.pos = len(p.src)
+		return
+	}
+	comment := p.src[p.pos+2 : p.pos+2+end]
+	p.pos += end + 4
+	if strings.EqualFold(strings.TrimSpace(comment), "GraphQL") {
+		p.prev = tokenComment
+	}
+}
+
+// skipRegexp skips regular expression literal like /[/`]+/g
+func (p *plucker) skipRegexp() {
+	p.pos++ // opening /
+	inClass := false
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == '\\':
+			p.pos++
+		case c == '\n':
+			return
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			p.scanIdent() // flags
+			return
+		}
+	}
+}
+
+func (p *plucker) scanIdent() string {
+	start := p.pos
+	for p.pos < len(p.src) && isIdentPart(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// scanString scans '...' or "..." string and returns its value
+func (p *plucker) scanString(quote byte) string {
+	p.pos++ // opening quote
+	var value strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\':
+			value.WriteString(p.scanEscape())
+		case c == quote:
+			p.pos++
+			return value.String()
+		case c == '\n':
+			// unterminated string
+			return value.String()
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+	return value.String()
+}
+
+// scanTemplate scans `...` template literal and returns its value, ${...} expressions are replaced with spaces.
+// Expressions are usually fragments like ${USER_FIELDS}, which are resolved by name from all the scanned documents
+func (p *plucker) scanTemplate() string {
+	p.pos++ // opening backtick
+	var value strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\':
+			value.WriteString(p.scanEscape())
+		case c == '`':
+			p.pos++
+			return value.String()
+		case c == '$' && p.peek(1) == '{':
+			start := p.pos
+			p.pos += 2
+			p.prev = tokenOperator
+			p.scanCode(true)
+			value.WriteString(blank(p.src[start:p.pos]))
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+	return value.String()
+}
+
+// scanEscape scans escape sequence like \` and returns the character it stands for
+func (p *plucker) scanEscape() string {
+	next := p.peek(1)
+	p.pos += 2
+	switch next {
+	case 0:
+		return ""
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '\n':
+		// line continuation
+		return ""
+	case 'u':
+		// GraphQL strings support the same unicode escapes
+		return "\\u"
+	}
+	return string(next)
+}
+
+func (p *plucker) addDocument(start int, content string) {
+	line, column := position(p.src, start)
+	p.documents = append(p.documents, &Document{
+		File:    p.fileName,
+		Line:    line,
+		Column:  column,
+		Content: content,
+	})
+}
+
+// blank replaces everything except line breaks with spaces, so positions of the following text don't change
+func blank(src string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' {
+			return r
+		}
+		return ' '
+	}, src)
+}
+
+// position returns 1-based line and column of a byte offset in the source
+func position(src string, offset int) (int, int) {
+	line := 1 + strings.Count(src[:offset], "\n")
+	lineStart := strings.LastIndexByte(src[:offset], '\n') + 1
+	column := 1 + utf8.RuneCountInString(src[lineStart:offset])
+	return line, column
+}
+
+func isIdentPart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '$' || c >= utf8.RuneSelf
+}
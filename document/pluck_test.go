@@ -0,0 +1,195 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPluck(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		src      string
+		want     []string
+	}{
+		{
+			name: "gql tag",
+			src:  "const q = gql`query A { a }`;",
+			want: []string{"query A { a }"},
+		},
+		{
+			name: "graphql tag",
+			src:  "const q = graphql`query A { a }`;",
+			want: []string{"query A { a }"},
+		},
+		{
+			name: "gql call with template",
+			src:  "const q = gql(`query A { a }`);",
+			want: []string{"query A { a }"},
+		},
+		{
+			name: "graphql call with double quoted string",
+			src:  `const q = graphql("query A { a }");`,
+			want: []string{"query A { a }"},
+		},
+		{
+			name: "gql call with single quoted string and escapes",
+			src:  `const q = gql('query A { a(s: \"it\'s\") }');`,
+			want: []string{`query A { a(s: "it's") }`},
+		},
+		{
+			name: "GraphQL comment marker",
+			src:  "const q = /* GraphQL */ `query A { a }`;",
+			want: []string{"query A { a }"},
+		},
+		{
+			name: "untagged template and string are ignored",
+			src:  "const a = `query A { a }`; const b = \"query B { b }\"; const c = other`query C { c }`;",
+		},
+		{
+			name: "other block comment isn't a marker",
+			src:  "const q = /* not graphql */ `query A { a }`;",
+		},
+		{
+			name: "escaped backtick",
+			src:  "const q = gql`query A { a(s: \"\\`\") }`; const b = gql`query B { b }`;",
+			want: []string{"query A { a(s: \"`\") }", "query B { b }"},
+		},
+		{
+			name: "interpolation is blanked",
+			src:  "const q = gql`query A { ...F }\n${FRAGMENT}`;",
+			want: []string{"query A { ...F }\n" + strings.Repeat(" ", len("${FRAGMENT}"))},
+		},
+		{
+			name: "backticks and braces in interpolation",
+			src:  "const q = gql`query A { a }${x ? `}` : { b: '`' }}`; const b = gql`query B { b }`;",
+			want: []string{"query A { a }" + strings.Repeat(" ", len("${x ? `}` : { b: '`' }}")), "query B { b }"},
+		},
+		{
+			name: "document in interpolation",
+			src:  "const q = gql`query A { ...F } ${gql`fragment F on Query { a }`}`;",
+			want: []string{"fragment F on Query { a }", "query A { ...F } " + strings.Repeat(" ", len("${gql`fragment F on Query { a }`}"))},
+		},
+		{
+			name: "line comment",
+			src:  "// gql`query A { a }`\nconst q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "apostrophe in line comment",
+			src:  "// don't\nconst q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "block comment with backtick",
+			src:  "/* ` */ const q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "backtick in string",
+			src:  "const s = '`'; const q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "regular expression with backtick",
+			src:  "const r = /[`/]+/g; const q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "regular expression after return",
+			src:  "function f(x) { return /`/.test(x); }\nconst q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "regular expression after if condition",
+			src:  "if (x) /`/.test(y)\nconst q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "regular expression after while condition with nested parentheses",
+			src:  "while (f(x)) /'/.exec(y)\nconst q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "division",
+			src:  "const a = b / c / gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name: "division after parentheses",
+			src:  "const a = (b + c) / 2; const s = '/'; const q = gql`query B { b }`;",
+			want: []string{"query B { b }"},
+		},
+		{
+			name:     "apostrophe in JSX text",
+			fileName: "component.tsx",
+			src:      "const d = <div>Don't {x}</div>; const i = gql`query I { i }`;",
+			want:     []string{"query I { i }"},
+		},
+		{
+			name:     "nested JSX with attributes and fragments",
+			fileName: "component.jsx",
+			src: "function C() {\n  return (\n    <>\n      <p title=\"it's\" onClick={() => f(`}`)}>It's <b>{x}</b></p>\n" +
+				"      <br />\n    </>\n  );\n}\nconst i = gql`query I { i }`;",
+			want: []string{"query I { i }"},
+		},
+		{
+			name:     "document in JSX expression",
+			fileName: "component.tsx",
+			src:      "const d = <Query query={gql`query I { i }`}>Don't</Query>;",
+			want:     []string{"query I { i }"},
+		},
+		{
+			name:     "comparison isn't JSX",
+			fileName: "component.tsx",
+			src:      "if (a < b && c > d) { f() }\nconst i = gql`query I { i }`;",
+			want:     []string{"query I { i }"},
+		},
+		{
+			name:     "type assertion in .ts isn't JSX",
+			fileName: "query.ts",
+			src:      "const s = <string>value; const i = gql`query I { i }`;",
+			want:     []string{"query I { i }"},
+		},
+		{
+			name:     "generic call isn't JSX",
+			fileName: "hook.tsx",
+			src:      "const r = useQuery<Data>(gql`query I { i }`);",
+			want:     []string{"query I { i }"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := test.fileName
+			if fileName == "" {
+				fileName = "query.ts"
+			}
+			var got []string
+			for _, document := range Pluck(fileName, test.src) {
+				got = append(got, document.Content)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPluckPosition(t *testing.T) {
+	src := "import gql from 'graphql-tag';\n\nexport const q = gql`\n  query A { a }\n`;\nconst é = gql`query B { b }`;"
+	documents := Pluck("query.ts", src)
+	if len(documents) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(documents))
+	}
+	positions := [][2]int{{documents[0].Line, documents[0].Column}, {documents[1].Line, documents[1].Column}}
+	want := [][2]int{{3, 22}, {6, 15}}
+	if !reflect.DeepEqual(positions, want) {
+		t.Fatalf("got positions %v, want %v", positions, want)
+	}
+	for _, document := range documents {
+		if document.File != "query.ts" {
+			t.Fatalf("expected file name query.ts, got %s", document.File)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package document
+
+import (
+	"fmt"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+	"recodegen/errs"
+)
+
+// LoadQuery parses documents and validates them against the schema as a single query document.
+// Fragments which are used, but not defined in the documents are taken from any other scanned file,
+// so fragments can be shared without listing their files in every output.
+// Each document is parsed separately and all errors are returned as ErrorList with positions in source files,
+// wrapped into errs.DocumentError
+func (set *Set) LoadQuery(schemaAst *ast.Schema, documents []*Document) (*ast.QueryDocument, error) {
+	query := &ast.QueryDocument{}
+	sources := make(map[string]*Document)
+	var errorList ErrorList
+	for _, document := range documents {
+		sources[document.sourceName()] = document
+		documentAst, err := document.parse()
+		if err != nil {
+			errorList = append(errorList, newError(err, sources))
+			continue
+		}
+		query.Operations = append(query.Operations, documentAst.Operations...)
+		query.Fragments = append(query.Fragments, documentAst.Fragments...)
+	}
+
+	set.addMissingFragments(query, sources)
+
+	for _, err := range validator.Validate(schemaAst, query) {
+		errorList = append(errorList, newError(err, sources))
+	}
+	if len(errorList) > 0 {
+		errorList.sort()
+		return nil, &errs.DocumentError{Err: errorList}
+	}
+	return query, nil
+}
+
+// addMissingFragments adds definitions of fragments which are spread in the query, but not defined in it
+func (set *Set) addMissingFragments(query *ast.QueryDocument, sources map[string]*Document) {
+	for {
+		isAdded := false
+		for _, name := range findMissingFragments(query) {
+			document, isFound := set.fragments[name]
+			if !isFound {
+				// will be reported by validation as unknown fragment
+				continue
+			}
+			// document is parsed again, so each output gets its own AST which is safe to modify by validation,
+			// it can't fail since only successfully parsed documents are indexed
+			documentAst, err := document.parse()
+			if err != nil {
+				continue
+			}
+			sources[document.sourceName()] = document
+			query.Fragments = append(query.Fragments, documentAst.Fragments.ForName(name))
+			isAdded = true
+		}
+		// added fragments can use other fragments
+		if !isAdded {
+			return
+		}
+	}
+}
+
+// findMissingFragments returns names of fragments which are spread, but not defined in the query
+func findMissingFragments(query *ast.QueryDocument) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	visit := func(name string) {
+		if seen[name] || query.Fragments.ForName(name) != nil {
+			return
+		}
+		seen[name] = true
+		missing = append(missing, name)
+	}
+	for _, operation := range query.Operations {
+		walkFragmentSpreads(operation.SelectionSet, visit)
+	}
+	for _, fragment := range query.Fragments {
+		walkFragmentSpreads(fragment.SelectionSet, visit)
+	}
+	return missing
+}
+
+func walkFragmentSpreads(selectionSet ast.SelectionSet, visit func(name string)) {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			walkFragmentSpreads(selection.SelectionSet, visit)
+		case *ast.InlineFragment:
+			walkFragmentSpreads(selection.SelectionSet, visit)
+		case *ast.FragmentSpread:
+			visit(selection.Name)
+		}
+	}
+}
+
+func (document *Document) parse() (*ast.QueryDocument, error) {
+	documentAst, err := parser.ParseQuery(&ast.Source{Name: document.sourceName(), Input: document.Content})
+	if err != nil {
+		return nil, err
+	}
+	return documentAst, nil
+}
+
+// sourceName identifies the document in parser and validator errors, a file can contain many documents
+func (document *Document) sourceName() string {
+	return fmt.Sprintf("%s:%d:%d", document.File, document.Line, document.Column)
+}
@@ -0,0 +1,60 @@
+// Package errs contains error types returned by code generation,
+// so callers can tell a broken config from an invalid schema or a failed write
+package errs
+
+import "fmt"
+
+// ConfigError means config file can't be read or has invalid content
+type ConfigError struct {
+	File string
+	Err  error
+}
+
+func (err *ConfigError) Error() string {
+	return fmt.Sprintf("unable to read config %s: %s", err.File, err.Err)
+}
+
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
+
+// SchemaError means schema can't be parsed or it's invalid
+type SchemaError struct {
+	Err error
+}
+
+func (err *SchemaError) Error() string {
+	return fmt.Sprintf("invalid schema: %s", err.Err)
+}
+
+func (err *SchemaError) Unwrap() error {
+	return err.Err
+}
+
+// DocumentError means documents have syntax errors or don't pass validation against the schema,
+// Err is usually document.ErrorList with positions of all the errors
+type DocumentError struct {
+	Err error
+}
+
+func (err *DocumentError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *DocumentError) Unwrap() error {
+	return err.Err
+}
+
+// IOError means a file can't be read or written
+type IOError struct {
+	Path string
+	Err  error
+}
+
+func (err *IOError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *IOError) Unwrap() error {
+	return err.Err
+}
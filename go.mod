@@ -6,6 +6,7 @@ require (
 	github.com/bmatcuk/doublestar/v4 v4.6.0
 	github.com/vektah/gqlparser/v2 v2.5.1
 	golang.org/x/text v0.9.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/agnivade/levenshtein v1.0.1 // indirect
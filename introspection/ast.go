@@ -0,0 +1,203 @@
+package introspection
+
+import (
+	"github.com/vektah/gqlparser/v2/ast"
+	"sort"
+	"strings"
+)
+
+// FromAST builds introspection result of a schema, types and directives are sorted by name,
+// descriptions are null if withDescriptions is false
+func FromAST(schemaAst *ast.Schema, withDescriptions bool) *Schema {
+	converter := astConverter{schemaAst: schemaAst, withDescriptions: withDescriptions}
+	schema := &Schema{
+		Description:      converter.description(schemaAst.Description),
+		QueryType:        typeName(schemaAst.Query),
+		MutationType:     typeName(schemaAst.Mutation),
+		SubscriptionType: typeName(schemaAst.Subscription),
+		Types:            []Type{},
+		Directives:       []Directive{},
+	}
+
+	typeNames := make([]string, 0, len(schemaAst.Types))
+	for name := range schemaAst.Types {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+	for _, name := range typeNames {
+		schema.Types = append(schema.Types, converter.convertType(schemaAst.Types[name]))
+	}
+
+	directiveNames := make([]string, 0, len(schemaAst.Directives))
+	for name := range schemaAst.Directives {
+		directiveNames = append(directiveNames, name)
+	}
+	sort.Strings(directiveNames)
+	for _, name := range directiveNames {
+		directive := schemaAst.Directives[name]
+		locations := make([]string, 0, len(directive.Locations))
+		for _, location := range directive.Locations {
+			locations = append(locations, string(location))
+		}
+		schema.Directives = append(schema.Directives, Directive{
+			Name:         directive.Name,
+			Description:  converter.description(directive.Description),
+			Locations:    locations,
+			Args:         converter.convertArgs(directive.Arguments),
+			IsRepeatable: directive.IsRepeatable,
+		})
+	}
+	return schema
+}
+
+type astConverter struct {
+	schemaAst        *ast.Schema
+	withDescriptions bool
+}
+
+func (converter astConverter) convertType(def *ast.Definition) Type {
+	result := Type{
+		Kind:        string(def.Kind),
+		Name:        def.Name,
+		Description: converter.description(def.Description),
+	}
+	switch def.Kind {
+	case ast.Scalar:
+		if directive := def.Directives.ForName("specifiedBy"); directive != nil {
+			if url := directive.Arguments.ForName("url"); url != nil && url.Value != nil {
+				result.SpecifiedByURL = &url.Value.Raw
+			}
+		}
+	case ast.Object, ast.Interface:
+		result.Fields = []Field{}
+		for _, field := range def.Fields {
+			// __schema and __type are added to the query type by the parser, they are not its own fields
+			if strings.HasPrefix(field.Name, "__") {
+				continue
+			}
+			isDeprecated, deprecationReason := deprecation(field.Directives)
+			result.Fields = append(result.Fields, Field{
+				Name:              field.Name,
+				Description:       converter.description(field.Description),
+				Args:              converter.convertArgs(field.Arguments),
+				Type:              converter.typeRef(field.Type),
+				IsDeprecated:      isDeprecated,
+				DeprecationReason: deprecationReason,
+			})
+		}
+		result.Interfaces = []TypeRef{}
+		for _, name := range def.Interfaces {
+			result.Interfaces = append(result.Interfaces, converter.namedTypeRef(name))
+		}
+		if def.Kind == ast.Interface {
+			result.PossibleTypes = converter.possibleTypes(def)
+		}
+	case ast.Union:
+		result.PossibleTypes = converter.possibleTypes(def)
+	case ast.Enum:
+		result.EnumValues = []EnumValue{}
+		for _, enumValue := range def.EnumValues {
+			isDeprecated, deprecationReason := deprecation(enumValue.Directives)
+			result.EnumValues = append(result.EnumValues, EnumValue{
+				Name:              enumValue.Name,
+				Description:       converter.description(enumValue.Description),
+				IsDeprecated:      isDeprecated,
+				DeprecationReason: deprecationReason,
+			})
+		}
+	case ast.InputObject:
+		result.InputFields = []InputValue{}
+		for _, field := range def.Fields {
+			result.InputFields = append(result.InputFields, converter.inputValue(field.Name, field.Description, field.Type, field.DefaultValue))
+		}
+	}
+	return result
+}
+
+// possibleTypes returns object types of a union in the declared order or objects implementing an interface
+// sorted by name, they are in the order of definitions otherwise which isn't kept by SDL
+func (converter astConverter) possibleTypes(def *ast.Definition) []TypeRef {
+	typeRefs := []TypeRef{}
+	for _, possibleType := range converter.schemaAst.GetPossibleTypes(def) {
+		if possibleType.Kind == ast.Object {
+			typeRefs = append(typeRefs, converter.namedTypeRef(possibleType.Name))
+		}
+	}
+	if def.Kind == ast.Interface {
+		sort.Slice(typeRefs, func(i, j int) bool {
+			return *typeRefs[i].Name < *typeRefs[j].Name
+		})
+	}
+	return typeRefs
+}
+
+func (converter astConverter) convertArgs(args ast.ArgumentDefinitionList) []InputValue {
+	inputValues := []InputValue{}
+	for _, arg := range args {
+		inputValues = append(inputValues, converter.inputValue(arg.Name, arg.Description, arg.Type, arg.DefaultValue))
+	}
+	return inputValues
+}
+
+func (converter astConverter) inputValue(name string, description string, astType *ast.Type, defaultValue *ast.Value) InputValue {
+	inputValue := InputValue{
+		Name:        name,
+		Description: converter.description(description),
+		Type:        converter.typeRef(astType),
+	}
+	if defaultValue != nil {
+		value := defaultValue.String()
+		inputValue.DefaultValue = &value
+	}
+	return inputValue
+}
+
+// typeRef converts a type like [User!]! into nested NON_NULL and LIST references
+func (converter astConverter) typeRef(astType *ast.Type) TypeRef {
+	if astType.NonNull {
+		nullable := *astType
+		nullable.NonNull = false
+		ofType := converter.typeRef(&nullable)
+		return TypeRef{Kind: "NON_NULL", OfType: &ofType}
+	}
+	if astType.Elem != nil {
+		ofType := converter.typeRef(astType.Elem)
+		return TypeRef{Kind: "LIST", OfType: &ofType}
+	}
+	return converter.namedTypeRef(astType.NamedType)
+}
+
+func (converter astConverter) namedTypeRef(name string) TypeRef {
+	typeRef := TypeRef{Name: &name}
+	if def := converter.schemaAst.Types[name]; def != nil {
+		typeRef.Kind = string(def.Kind)
+	}
+	return typeRef
+}
+
+func (converter astConverter) description(description string) *string {
+	if !converter.withDescriptions || description == "" {
+		return nil
+	}
+	return &description
+}
+
+// deprecation returns deprecation reason from @deprecated directive
+func deprecation(directives ast.DirectiveList) (bool, *string) {
+	directive := directives.ForName("deprecated")
+	if directive == nil {
+		return false, nil
+	}
+	reason := defaultDeprecationReason
+	if arg := directive.Arguments.ForName("reason"); arg != nil && arg.Value != nil {
+		reason = arg.Value.Raw
+	}
+	return true, &reason
+}
+
+func typeName(def *ast.Definition) *TypeName {
+	if def == nil {
+		return nil
+	}
+	return &TypeName{Name: def.Name}
+}
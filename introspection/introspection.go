@@ -0,0 +1,101 @@
+// Package introspection reads and writes GraphQL introspection query results,
+// so a schema published as schema.json can be used the same way as SDL
+package introspection
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// Schema is the "__schema" field of an introspection query result
+type Schema struct {
+	Description      *string     `json:"description,omitempty"`
+	QueryType        *TypeName   `json:"queryType"`
+	MutationType     *TypeName   `json:"mutationType"`
+	SubscriptionType *TypeName   `json:"subscriptionType"`
+	Types            []Type      `json:"types"`
+	Directives       []Directive `json:"directives"`
+}
+
+type TypeName struct {
+	Name string `json:"name"`
+}
+
+// Type is a full description of a named type, fields which don't apply to the type kind are null
+type Type struct {
+	Kind           string       `json:"kind"`
+	Name           string       `json:"name"`
+	Description    *string      `json:"description"`
+	SpecifiedByURL *string      `json:"specifiedByURL,omitempty"`
+	Fields         []Field      `json:"fields"`
+	InputFields    []InputValue `json:"inputFields"`
+	Interfaces     []TypeRef    `json:"interfaces"`
+	EnumValues     []EnumValue  `json:"enumValues"`
+	PossibleTypes  []TypeRef    `json:"possibleTypes"`
+}
+
+type Field struct {
+	Name              string       `json:"name"`
+	Description       *string      `json:"description"`
+	Args              []InputValue `json:"args"`
+	Type              TypeRef      `json:"type"`
+	IsDeprecated      bool         `json:"isDeprecated"`
+	DeprecationReason *string      `json:"deprecationReason"`
+}
+
+// InputValue is an argument or an input object field, DefaultValue is a GraphQL literal like "10" or "\"text\""
+type InputValue struct {
+	Name         string  `json:"name"`
+	Description  *string `json:"description"`
+	Type         TypeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+type EnumValue struct {
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+// TypeRef is a reference to a type, NON_NULL and LIST wrap another reference in OfType
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   *string  `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+type Directive struct {
+	Name         string       `json:"name"`
+	Description  *string      `json:"description"`
+	Locations    []string     `json:"locations"`
+	Args         []InputValue `json:"args"`
+	IsRepeatable bool         `json:"isRepeatable"`
+}
+
+// Result is an introspection query result, "data" is set when it's a full GraphQL response
+type Result struct {
+	Data   *Result `json:"data,omitempty"`
+	Schema *Schema `json:"__schema,omitempty"`
+}
+
+// IsIntrospection reports if content looks like JSON, SDL can't start with "{"
+func IsIntrospection(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("{"))
+}
+
+// Parse reads introspection result as { "data": { "__schema": ... } } or { "__schema": ... }
+func Parse(content []byte) (*Schema, error) {
+	var result Result
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, err
+	}
+	if result.Data != nil {
+		result = *result.Data
+	}
+	if result.Schema == nil {
+		return nil, errors.New(`introspection result has no "__schema" field`)
+	}
+	return result.Schema, nil
+}
@@ -0,0 +1,157 @@
+package introspection
+
+import (
+	"encoding/json"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const roundTripSDL = `
+"Schema with custom root names"
+schema {
+  query: query_root
+  mutation: mutation_root
+}
+
+"Marks cached fields"
+directive @cached(ttl: Int = 60) repeatable on FIELD_DEFINITION | OBJECT
+
+"UUID"
+scalar uuid @specifiedBy(url: "https://tools.ietf.org/html/rfc4122")
+
+scalar jsonb
+
+type query_root {
+  "Finds a user"
+  user(id: uuid!): User @cached(ttl: 30)
+  users(where: UserFilter, limit: Int = 10, order: [Order!] = [ASC]): [User!]!
+  search(text: String = "a \"quoted\" text"): [SearchResult!]!
+  oldUsers: [User] @deprecated
+}
+
+type mutation_root {
+  updateUser(id: uuid!, set: UserInput!): User
+}
+
+interface Node {
+  id: uuid!
+}
+
+interface Named implements Node {
+  id: uuid!
+  name: String
+}
+
+type User implements Node & Named @cached {
+  id: uuid!
+  name: String
+  "User settings"
+  settings(path: String): jsonb
+  role: Role!
+  nickname: String @deprecated(reason: "Use name")
+}
+
+type Post implements Node {
+  id: uuid!
+  title: String
+}
+
+union SearchResult = User | Post
+
+enum Role {
+  ADMIN
+  USER
+  GUEST @deprecated(reason: "Guests can't log in")
+}
+
+enum Order {
+  ASC
+  DESC
+}
+
+input UserFilter {
+  name: String = "anonymous"
+  role: Role = USER
+  ids: [uuid!]
+  and: [UserFilter!]
+  settings: jsonb = {theme: "dark", size: 2}
+}
+
+input UserInput {
+  name: String
+  active: Boolean = true
+  score: Float = 1.5
+}
+`
+
+func loadSDL(t *testing.T, name string, sdl string) *ast.Schema {
+	t.Helper()
+	schemaAst, err := gqlparser.LoadSchema(&ast.Source{Name: name, Input: sdl})
+	if err != nil {
+		t.Fatalf("unable to load %s: %s\n%s", name, err, sdl)
+	}
+	return schemaAst
+}
+
+// TestRoundTrip converts SDL to introspection JSON and back and checks that nothing is lost,
+// except applied custom directives like @cached which introspection doesn't have
+func TestRoundTrip(t *testing.T) {
+	original := FromAST(loadSDL(t, "schema.graphql", roundTripSDL), true)
+
+	content, err := json.Marshal(Result{Data: &Result{Schema: original}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdl := parsed.SDL()
+	roundTripped := FromAST(loadSDL(t, "generated.graphql", sdl), true)
+
+	if !reflect.DeepEqual(roundTripped, original) {
+		originalJSON, _ := json.MarshalIndent(original, "", "  ")
+		roundTrippedJSON, _ := json.MarshalIndent(roundTripped, "", "  ")
+		t.Fatalf("schema changed after round trip through SDL:\n%s\noriginal:\n%s\nround tripped:\n%s",
+			sdl, originalJSON, roundTrippedJSON)
+	}
+
+	for _, want := range []string{
+		"schema {",
+		"query: query_root",
+		"mutation: mutation_root",
+		`@specifiedBy(url: "https://tools.ietf.org/html/rfc4122")`,
+		`@deprecated(reason: "Use name")`,
+		"limit: Int = 10",
+		"directive @cached(ttl: Int = 60) repeatable on FIELD_DEFINITION | OBJECT",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("expected %q in SDL:\n%s", want, sdl)
+		}
+	}
+}
+
+func TestRoundTripDefaultRootNames(t *testing.T) {
+	schemaAst := loadSDL(t, "schema.graphql", "type Query { a: String }\ntype Mutation { b: String }")
+	sdl := FromAST(schemaAst, true).SDL()
+	if strings.Contains(sdl, "schema {") {
+		t.Fatalf("schema definition isn't needed for default root names:\n%s", sdl)
+	}
+	if roundTripped := loadSDL(t, "generated.graphql", sdl); roundTripped.Mutation == nil || roundTripped.Mutation.Name != "Mutation" {
+		t.Fatalf("mutation root is lost:\n%s", sdl)
+	}
+}
+
+func TestFromASTWithoutDescriptions(t *testing.T) {
+	schema := FromAST(loadSDL(t, "schema.graphql", roundTripSDL), false)
+	content, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "Finds a user") {
+		t.Fatal("descriptions should be left out")
+	}
+}
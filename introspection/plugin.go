@@ -0,0 +1,33 @@
+package introspection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"recodegen/plugin"
+)
+
+func init() {
+	plugin.Register("introspection", Plugin{})
+}
+
+// Plugin writes introspection result of the schema as JSON, it's "introspection" plugin.
+// Output is indented unless "minify" is set, "descriptions": false leaves out descriptions
+type Plugin struct{}
+
+func (Plugin) Generate(ctx context.Context, input *plugin.Input) (string, error) {
+	pluginConfig := input.Config.Config
+	withDescriptions := pluginConfig.Descriptions == nil || *pluginConfig.Descriptions
+	result := Result{Schema: FromAST(input.Schema, withDescriptions)}
+
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	if pluginConfig.Minify == nil || !*pluginConfig.Minify {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(result); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
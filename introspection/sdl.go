@@ -0,0 +1,209 @@
+package introspection
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// types and directives which are defined by every GraphQL schema, they are not written to SDL
+var builtinTypes = map[string]bool{
+	"String":  true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+var builtinDirectives = map[string]bool{
+	"include":     true,
+	"skip":        true,
+	"deprecated":  true,
+	"specifiedBy": true,
+}
+
+// IsBuiltinDirective returns true if the directive is defined by every GraphQL schema
+func IsBuiltinDirective(name string) bool {
+	return builtinDirectives[name]
+}
+
+// default deprecation reason, it's not written to SDL
+const defaultDeprecationReason = "No longer supported"
+
+// SDL returns the schema in GraphQL schema definition language,
+// built-in scalars, directives and introspection types are skipped
+func (schema *Schema) SDL() string {
+	var sdl strings.Builder
+	writeSchemaDefinition(&sdl, schema)
+	for _, directive := range schema.Directives {
+		if builtinDirectives[directive.Name] {
+			continue
+		}
+		writeDescription(&sdl, directive.Description, "")
+		sdl.WriteString("directive @" + directive.Name)
+		writeArgs(&sdl, directive.Args)
+		if directive.IsRepeatable {
+			sdl.WriteString(" repeatable")
+		}
+		sdl.WriteString(" on " + strings.Join(directive.Locations, " | ") + "\n\n")
+	}
+	for _, def := range schema.Types {
+		if builtinTypes[def.Name] || strings.HasPrefix(def.Name, "__") {
+			continue
+		}
+		writeType(&sdl, def)
+	}
+	return sdl.String()
+}
+
+// writeSchemaDefinition writes "schema { ... }" if root types don't have default names
+func writeSchemaDefinition(sdl *strings.Builder, schema *Schema) {
+	roots := []struct {
+		operation   string
+		typeName    *TypeName
+		defaultName string
+	}{
+		{"query", schema.QueryType, "Query"},
+		{"mutation", schema.MutationType, "Mutation"},
+		{"subscription", schema.SubscriptionType, "Subscription"},
+	}
+	isDefault := true
+	for _, root := range roots {
+		if root.typeName != nil && root.typeName.Name != root.defaultName {
+			isDefault = false
+		}
+	}
+	if isDefault {
+		return
+	}
+
+	writeDescription(sdl, schema.Description, "")
+	sdl.WriteString("schema {\n")
+	for _, root := range roots {
+		if root.typeName != nil {
+			sdl.WriteString("  " + root.operation + ": " + root.typeName.Name + "\n")
+		}
+	}
+	sdl.WriteString("}\n\n")
+}
+
+func writeType(sdl *strings.Builder, def Type) {
+	writeDescription(sdl, def.Description, "")
+	switch def.Kind {
+	case "SCALAR":
+		sdl.WriteString("scalar " + def.Name)
+		if def.SpecifiedByURL != nil {
+			sdl.WriteString(" @specifiedBy(url: " + quote(*def.SpecifiedByURL) + ")")
+		}
+		sdl.WriteString("\n\n")
+	case "OBJECT", "INTERFACE":
+		keyword := "type "
+		if def.Kind == "INTERFACE" {
+			keyword = "interface "
+		}
+		sdl.WriteString(keyword + def.Name + implements(def.Interfaces) + " {\n")
+		for _, field := range def.Fields {
+			writeDescription(sdl, field.Description, "  ")
+			sdl.WriteString("  " + field.Name)
+			writeArgs(sdl, field.Args)
+			sdl.WriteString(": " + field.Type.String())
+			writeDeprecated(sdl, field.IsDeprecated, field.DeprecationReason)
+			sdl.WriteString("\n")
+		}
+		sdl.WriteString("}\n\n")
+	case "UNION":
+		var names []string
+		for _, possibleType := range def.PossibleTypes {
+			names = append(names, possibleType.String())
+		}
+		sdl.WriteString("union " + def.Name + " = " + strings.Join(names, " | ") + "\n\n")
+	case "ENUM":
+		sdl.WriteString("enum " + def.Name + " {\n")
+		for _, enumValue := range def.EnumValues {
+			writeDescription(sdl, enumValue.Description, "  ")
+			sdl.WriteString("  " + enumValue.Name)
+			writeDeprecated(sdl, enumValue.IsDeprecated, enumValue.DeprecationReason)
+			sdl.WriteString("\n")
+		}
+		sdl.WriteString("}\n\n")
+	case "INPUT_OBJECT":
+		sdl.WriteString("input " + def.Name + " {\n")
+		for _, inputField := range def.InputFields {
+			writeDescription(sdl, inputField.Description, "  ")
+			sdl.WriteString("  " + inputValue(inputField) + "\n")
+		}
+		sdl.WriteString("}\n\n")
+	}
+}
+
+// implements returns " implements A & B" or empty string if there are no interfaces
+func implements(interfaces []TypeRef) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	var names []string
+	for _, typeRef := range interfaces {
+		names = append(names, typeRef.String())
+	}
+	return " implements " + strings.Join(names, " & ")
+}
+
+func writeArgs(sdl *strings.Builder, args []InputValue) {
+	if len(args) == 0 {
+		return
+	}
+	sdl.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			sdl.WriteString(", ")
+		}
+		if arg.Description != nil && *arg.Description != "" {
+			sdl.WriteString(quote(*arg.Description) + " ")
+		}
+		sdl.WriteString(inputValue(arg))
+	}
+	sdl.WriteString(")")
+}
+
+func inputValue(value InputValue) string {
+	output := value.Name + ": " + value.Type.String()
+	if value.DefaultValue != nil {
+		output += " = " + *value.DefaultValue
+	}
+	return output
+}
+
+func writeDeprecated(sdl *strings.Builder, isDeprecated bool, reason *string) {
+	if !isDeprecated {
+		return
+	}
+	sdl.WriteString(" @deprecated")
+	if reason != nil && *reason != defaultDeprecationReason {
+		sdl.WriteString("(reason: " + quote(*reason) + ")")
+	}
+}
+
+func writeDescription(sdl *strings.Builder, description *string, indent string) {
+	if description == nil || *description == "" {
+		return
+	}
+	sdl.WriteString(indent + quote(*description) + "\n")
+}
+
+// quote returns a GraphQL string, JSON string escapes are valid in GraphQL
+func quote(text string) string {
+	quoted, _ := json.Marshal(text)
+	return string(quoted)
+}
+
+// String returns type reference in GraphQL syntax like [User!]!
+func (typeRef TypeRef) String() string {
+	switch {
+	case typeRef.Kind == "NON_NULL" && typeRef.OfType != nil:
+		return typeRef.OfType.String() + "!"
+	case typeRef.Kind == "LIST" && typeRef.OfType != nil:
+		return "[" + typeRef.OfType.String() + "]"
+	case typeRef.Name != nil:
+		return *typeRef.Name
+	}
+	return ""
+}
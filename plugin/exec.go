@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"os"
+	"os/exec"
+	"recodegen/config"
+	"recodegen/document"
+	"strings"
+	"time"
+)
+
+// ExecPrefix is a prefix of executables found on PATH for plugin names which are not registered,
+// e.g. "my-types" plugin runs "recodegen-plugin-my-types"
+const ExecPrefix = "recodegen-plugin-"
+
+// DefaultExecTimeout limits how long an external plugin can run when Exec.Timeout is not set
+const DefaultExecTimeout = 2 * time.Minute
+
+// Exec is a plugin run as an external process, it gets ExecRequest as JSON on stdin
+// and writes generated content to stdout. Non-zero exit code fails generation, stderr is used as error message.
+// Process is killed when input context is cancelled or Timeout passes
+type Exec struct {
+	Path string
+	// Timeout is DefaultExecTimeout if not set
+	Timeout time.Duration
+}
+
+// ExecRequest is sent to external plugins on stdin
+type ExecRequest struct {
+	FileName string `json:"fileName"`
+	// Schema in SDL format
+	Schema string `json:"schema"`
+	// Documents as they were extracted from source files
+	Documents []*document.Document             `json:"documents"`
+	Config    *config.CodegenSchemaEntryConfig `json:"config"`
+}
+
+// Resolve returns registered plugin or an external plugin. External plugin is either a path to an executable
+// like "./tools/my-plugin" or a name of ExecPrefix executable on PATH, error names the executable which wasn't found
+func Resolve(name string) (Plugin, error) {
+	if plugin, isRegistered := Lookup(name); isRegistered {
+		return plugin, nil
+	}
+
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, os.PathSeparator) {
+		if _, err := os.Stat(name); err != nil {
+			return nil, fmt.Errorf("plugin %s not found: %w", name, err)
+		}
+		return &Exec{Path: name}, nil
+	}
+
+	path, err := exec.LookPath(ExecPrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s is not built in and %s is not found on PATH", name, ExecPrefix+name)
+	}
+	return &Exec{Path: path}, nil
+}
+
+func (plugin *Exec) Generate(ctx context.Context, input *Input) (string, error) {
+	request := ExecRequest{
+		FileName:  input.FileName,
+		Schema:    getSchemaSDL(input),
+		Documents: input.Documents,
+		Config:    input.Config,
+	}
+	if request.Documents == nil {
+		request.Documents = []*document.Document{}
+	}
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := plugin.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin.Path)
+	cmd.Stdin = bytes.NewReader(requestJSON)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// children of a killed plugin can keep stdout open, so output isn't waited for long after that
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			return "", fmt.Errorf("plugin %s failed: %w", plugin.Path, err)
+		}
+		return "", fmt.Errorf("plugin %s failed: %w\n%s", plugin.Path, err, message)
+	}
+	return stdout.String(), nil
+}
+
+// getSchemaSDL formats schema once for all external plugins of the same schema
+func getSchemaSDL(input *Input) string {
+	if input.Cache != nil {
+		if sdl, isCached := input.Cache.Load(schemaSDLCacheKey{}); isCached {
+			return sdl.(string)
+		}
+	}
+	var sdl strings.Builder
+	formatter.NewFormatter(&sdl).FormatSchema(input.Schema)
+	if input.Cache != nil {
+		input.Cache.Store(schemaSDLCacheKey{}, sdl.String())
+	}
+	return sdl.String()
+}
+
+type schemaSDLCacheKey struct{}
@@ -0,0 +1,58 @@
+// Package plugin defines how output content is generated, every name in "plugins" config is a registered Plugin
+package plugin
+
+import (
+	"context"
+	"github.com/vektah/gqlparser/v2/ast"
+	"recodegen/config"
+	"recodegen/document"
+	"sync"
+)
+
+// Input is everything a plugin gets to generate content of an output file
+type Input struct {
+	// FileName is the output file name
+	FileName string
+	Schema   *ast.Schema
+	// Query has all operations and fragments of the output documents validated against the schema
+	Query *ast.QueryDocument
+	// Documents are the output documents as they were extracted from source files
+	Documents []*document.Document
+	// Config of the output with the global config merged in
+	Config *config.CodegenSchemaEntryConfig
+	// Cache is shared by all outputs generated from the same schema, so plugins can keep
+	// data derived from the schema there instead of computing it for every output
+	Cache *sync.Map
+}
+
+// Plugin generates content of an output file, content of all output plugins is concatenated.
+// ctx is cancelled when generation is stopped, e.g. on Ctrl+C in watch mode
+type Plugin interface {
+	Generate(ctx context.Context, input *Input) (string, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Plugin)
+)
+
+// Register makes plugin available by name in "plugins" config, it panics if the name is already taken
+func Register(name string, plugin Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if plugin == nil {
+		panic("plugin: Register plugin is nil")
+	}
+	if _, isRegistered := registry[name]; isRegistered {
+		panic("plugin: Register called twice for plugin " + name)
+	}
+	registry[name] = plugin
+}
+
+// Lookup returns plugin registered by name
+func Lookup(name string) (Plugin, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	plugin, isRegistered := registry[name]
+	return plugin, isRegistered
+}
@@ -0,0 +1,217 @@
+// Package recodegen generates TypeScript types from a GraphQL schema and documents,
+// it's the same code generation which is run by the recodegen binary, but nothing is written to disk
+package recodegen
+
+import (
+	"context"
+	"errors"
+	"github.com/vektah/gqlparser/v2/ast"
+	"recodegen/config"
+	"recodegen/document"
+	_ "recodegen/introspection"
+	"recodegen/plugin"
+	"recodegen/schema"
+	_ "recodegen/typescript"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Generate generates all output files described by config and returns their content by output file name.
+// Outputs without plugins are skipped. Paths in config are relative to the working directory
+func Generate(ctx context.Context, cfg config.CodegenConfig) (map[string]string, error) {
+	generator := Generator{Config: cfg}
+	results, err := generator.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]string)
+	var outputErrs []error
+	for _, result := range results {
+		if result.Err != nil {
+			outputErrs = append(outputErrs, result.Err)
+			continue
+		}
+		if result.IsSupported {
+			output[result.FileName] = result.Content
+		}
+	}
+	if len(outputErrs) > 0 {
+		return nil, errors.Join(outputErrs...)
+	}
+	return output, nil
+}
+
+// Generator runs code generation for all outputs of the config
+type Generator struct {
+	Config config.CodegenConfig
+	// Jobs is a number of output files generated in parallel, number of CPUs if not set
+	Jobs int
+	// SchemaLoader sets how schema URLs are fetched and cached
+	SchemaLoader schema.Loader
+
+	schemaAst *ast.Schema
+	documents *document.Set
+	cache     *sync.Map
+}
+
+// Result is generated content of a single output file
+type Result struct {
+	FileName string
+	Content  string
+	// IsSupported is false if the output has no plugins, such output shouldn't be written
+	IsSupported bool
+	// Overwrite is false if an existing file shouldn't be replaced with Content
+	Overwrite bool
+	Err       error
+}
+
+// Run loads schema and documents and generates all outputs. Error is returned if schema or documents
+// can't be loaded, errors of a particular output are reported in its Result.
+// Results are sorted by output file name no matter which one finished first
+func (generator *Generator) Run(ctx context.Context) ([]Result, error) {
+	if err := generator.Load(ctx); err != nil {
+		return nil, err
+	}
+	return generator.GenerateOutputs(ctx, generator.OutputFileNames()), nil
+}
+
+// Load loads schema and documents of all outputs, it has to be called before GenerateOutputs.
+// When only schema or only documents change, LoadSchema or LoadDocuments is enough
+func (generator *Generator) Load(ctx context.Context) error {
+	if err := generator.LoadSchema(ctx); err != nil {
+		return err
+	}
+	return generator.LoadDocuments()
+}
+
+// LoadSchema loads schema from files and URLs, data which plugins derived from the previous schema is dropped
+func (generator *Generator) LoadSchema(ctx context.Context) error {
+	schemaAst, err := generator.SchemaLoader.Load(ctx, generator.Config.Schema)
+	if err != nil {
+		return err
+	}
+	generator.schemaAst = schemaAst
+	generator.cache = &sync.Map{}
+	return nil
+}
+
+// LoadDocuments loads documents of all outputs, they are extracted once from all files used by any output
+func (generator *Generator) LoadDocuments() error {
+	documents, err := document.LoadSet(generator.DocumentPatterns())
+	if err != nil {
+		return err
+	}
+	generator.documents = documents
+	return nil
+}
+
+// Documents returns documents loaded by the last Load
+func (generator *Generator) Documents() *document.Set {
+	return generator.documents
+}
+
+// DocumentPatterns returns document glob patterns of all outputs
+func (generator *Generator) DocumentPatterns() []string {
+	var documentPatterns []string
+	for _, outputFileName := range generator.OutputFileNames() {
+		documentPatterns = append(documentPatterns, generator.Config.Generates[outputFileName].Documents.Paths()...)
+	}
+	return documentPatterns
+}
+
+// OutputFileNames returns sorted names of all outputs
+func (generator *Generator) OutputFileNames() []string {
+	outputFileNames := make([]string, 0, len(generator.Config.Generates))
+	for outputFileName := range generator.Config.Generates {
+		outputFileNames = append(outputFileNames, outputFileName)
+	}
+	sort.Strings(outputFileNames)
+	return outputFileNames
+}
+
+// GenerateOutputs generates given outputs using schema and documents from the last Load,
+// results are in the same order as outputFileNames
+func (generator *Generator) GenerateOutputs(ctx context.Context, outputFileNames []string) []Result {
+	jobs := generator.Jobs
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	return processInputs(ctx, outputFileNames, jobs, func(outputFileName string) Result {
+		genConfig := generator.Config.Generates[outputFileName]
+		genConfig.Config = generator.Config.Config.Merge(genConfig.Config)
+		result := processInput(ctx, generator.schemaAst, generator.cache, generator.documents, outputFileName, &genConfig)
+		result.Overwrite = generator.Config.ShouldOverwrite(outputFileName)
+		return result
+	})
+}
+
+// processInputs runs process for every output file using given number of workers,
+// results are returned in the same order as outputFileNames
+func processInputs(ctx context.Context, outputFileNames []string, jobs int, process func(outputFileName string) Result) []Result {
+	results := make([]Result, len(outputFileNames))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i] = Result{FileName: outputFileNames[i], Err: err}
+					continue
+				}
+				results[i] = process(outputFileNames[i])
+			}
+		}()
+	}
+	for i := range outputFileNames {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// processInput runs all plugins of the output, a plugin which is neither registered nor found as executable
+// fails the output before any plugin is run
+func processInput(ctx context.Context, schemaAst *ast.Schema, cache *sync.Map, documents *document.Set, outputFileName string, genConfig *config.CodegenSchemaEntryConfig) Result {
+	result := Result{FileName: outputFileName}
+	var outputPlugins []plugin.Plugin
+	for _, name := range genConfig.Plugins {
+		outputPlugin, err := plugin.Resolve(name)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		outputPlugins = append(outputPlugins, outputPlugin)
+	}
+
+	input := &plugin.Input{
+		FileName: outputFileName,
+		Schema:   schemaAst,
+		Config:   genConfig,
+		Cache:    cache,
+	}
+	if len(genConfig.Documents) > 0 {
+		input.Documents = documents.Select(genConfig.Documents.Paths())
+		query, err := documents.LoadQuery(schemaAst, input.Documents)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		input.Query = query
+	}
+
+	for _, outputPlugin := range outputPlugins {
+		result.IsSupported = true
+		content, err := outputPlugin.Generate(ctx, input)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Content += content
+	}
+	return result
+}
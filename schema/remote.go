@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/vektah/gqlparser/v2/ast"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"recodegen/config"
+	"recodegen/errs"
+	"recodegen/introspection"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout limits introspection query of a schema URL when Loader.Client is not set
+const DefaultTimeout = 30 * time.Second
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// matches ${NAME} reference to an environment variable in header values
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${NAME} with environment variable value, "$" not followed by {NAME} is kept as is,
+// so secrets with "$" in them aren't changed
+func expandEnv(value string) string {
+	return envRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		return os.Getenv(reference[2 : len(reference)-1])
+	})
+}
+
+// IsURL returns true if the schema path is fetched over HTTP
+func IsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// graphQLResponse is used to find errors in introspection response
+type graphQLResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// loadURL runs introspection query against url and converts result to SDL, result is cached,
+// so it can be used by Offline loader
+func (loader *Loader) loadURL(ctx context.Context, url string, options config.SourceOptions) (*ast.Source, error) {
+	cacheFileName, err := loader.cacheFileName(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if loader.Offline {
+		content, err = os.ReadFile(cacheFileName)
+		if err != nil {
+			return nil, &errs.IOError{Path: cacheFileName, Err: fmt.Errorf("no cached schema of %s for offline mode: %w", url, err)}
+		}
+	} else {
+		content, err = loader.fetch(ctx, url, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	introspectionSchema, err := introspection.Parse(content)
+	if err != nil {
+		return nil, &errs.SchemaError{Err: fmt.Errorf("%s: unable to parse introspection result: %w", url, err)}
+	}
+
+	if !loader.Offline {
+		if err := writeCache(cacheFileName, content); err != nil {
+			return nil, err
+		}
+	}
+	return &ast.Source{
+		Name:  url,
+		Input: introspectionSchema.SDL(),
+	}, nil
+}
+
+// fetch sends introspection query, header values can reference environment variables like ${TOKEN}
+func (loader *Loader) fetch(ctx context.Context, url string, options config.SourceOptions) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{"query": introspection.Query})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, &errs.IOError{Path: url, Err: err}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	for name, value := range options.Headers {
+		request.Header.Set(name, expandEnv(value))
+	}
+
+	client := loader.Client
+	if client == nil {
+		client = defaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, &errs.IOError{Path: url, Err: err}
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, &errs.IOError{Path: url, Err: fmt.Errorf("%s: unable to read introspection result: %w", url, err)}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, &errs.IOError{Path: url, Err: fmt.Errorf("%s: introspection query failed with status %s", url, response.Status)}
+	}
+
+	var graphQLErr graphQLResponse
+	if err := json.Unmarshal(content, &graphQLErr); err == nil && len(graphQLErr.Errors) > 0 {
+		var messages []string
+		for _, responseErr := range graphQLErr.Errors {
+			messages = append(messages, responseErr.Message)
+		}
+		return nil, &errs.SchemaError{Err: fmt.Errorf("%s: introspection query failed: %s", url, strings.Join(messages, "; "))}
+	}
+	return content, nil
+}
+
+// cacheFileName returns a cache file of url, file name is a hash of url
+func (loader *Loader) cacheFileName(url string) (string, error) {
+	cacheDir := loader.CacheDir
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", &errs.IOError{Path: url, Err: fmt.Errorf("unable to find cache directory for schema of %s", url)}
+		}
+		cacheDir = filepath.Join(userCacheDir, "recodegen")
+	}
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, "introspection-"+hex.EncodeToString(hash[:])+".json"), nil
+}
+
+func writeCache(fileName string, content []byte) error {
+	dir := filepath.Dir(fileName)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return &errs.IOError{Path: dir, Err: err}
+	}
+	if err := os.WriteFile(fileName, content, 0666); err != nil {
+		return &errs.IOError{Path: fileName, Err: err}
+	}
+	return nil
+}
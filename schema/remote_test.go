@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"recodegen/config"
+	"recodegen/errs"
+	"recodegen/introspection"
+	"strings"
+	"testing"
+	"time"
+)
+
+const remoteSDL = `
+type Query {
+  user(id: ID!): User
+}
+
+type User {
+  id: ID!
+  name: String
+}
+`
+
+// introspectionResponse returns introspection query response of remoteSDL
+func introspectionResponse(t *testing.T) []byte {
+	t.Helper()
+	schemaAst := gqlparser.MustLoadSchema(&ast.Source{Name: "remote.graphql", Input: remoteSDL})
+	content, err := json.Marshal(introspection.Result{
+		Data: &introspection.Result{Schema: introspection.FromAST(schemaAst, true)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func urlSources(url string, headers map[string]string) config.Sources {
+	return config.Sources{{Path: url, Options: config.SourceOptions{Headers: headers}}}
+}
+
+func TestLoadURL(t *testing.T) {
+	t.Setenv("RECODEGEN_TEST_SECRET", "secret")
+	response := introspectionResponse(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		if request.Method != http.MethodPost {
+			t.Errorf("expected POST request, got %s", request.Method)
+		}
+		if secret := request.Header.Get("X-Secret"); secret != "secret" {
+			t.Errorf("expected expanded header value, got %q", secret)
+		}
+		if token := request.Header.Get("X-Token"); token != "a$RECODEGEN_TEST_SECRET$b" {
+			t.Errorf("expected header value without ${NAME} to be sent as is, got %q", token)
+		}
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Query != introspection.Query {
+			t.Errorf("expected introspection query in request body, got %q, %v", body.Query, err)
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write(response)
+	}))
+	defer server.Close()
+
+	loader := Loader{Client: server.Client(), CacheDir: t.TempDir()}
+	sources := urlSources(server.URL, map[string]string{
+		"X-Secret": "${RECODEGEN_TEST_SECRET}",
+		"X-Token":  "a$RECODEGEN_TEST_SECRET$b",
+	})
+	schemaAst, err := loader.Load(context.Background(), sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaAst.Types["User"] == nil || schemaAst.Query.Fields.ForName("user") == nil {
+		t.Fatal("schema fetched from URL has no User type or Query.user field")
+	}
+
+	cacheFileName, err := loader.cacheFileName(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := os.ReadFile(cacheFileName)
+	if err != nil {
+		t.Fatalf("introspection result isn't cached: %s", err)
+	}
+	if string(cached) != string(response) {
+		t.Fatal("cached content differs from introspection response")
+	}
+
+	// offline mode reads the cache and sends nothing
+	loader.Offline = true
+	schemaAst, err = loader.Load(context.Background(), sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaAst.Types["User"] == nil {
+		t.Fatal("schema loaded from cache has no User type")
+	}
+	if requests != 1 {
+		t.Fatalf("expected a single request, got %d", requests)
+	}
+}
+
+func TestLoadURLOfflineWithoutCache(t *testing.T) {
+	loader := Loader{CacheDir: t.TempDir(), Offline: true}
+	_, err := loader.Load(context.Background(), urlSources("http://localhost/graphql", nil))
+	var ioErr *errs.IOError
+	if !errors.As(err, &ioErr) {
+		t.Fatalf("expected IOError, got %v", err)
+	}
+}
+
+func TestLoadURLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write([]byte(`{"errors":[{"message":"introspection is disabled"}]}`))
+	}))
+	defer server.Close()
+
+	loader := Loader{Client: server.Client(), CacheDir: t.TempDir()}
+	_, err := loader.Load(context.Background(), urlSources(server.URL, nil))
+	var schemaErr *errs.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected SchemaError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "introspection is disabled") {
+		t.Fatalf("expected error message from response, got %q", err)
+	}
+	cacheFileName, _ := loader.cacheFileName(server.URL)
+	if _, err := os.Stat(cacheFileName); err == nil {
+		t.Fatal("failed response shouldn't be cached")
+	}
+}
+
+func TestLoadURLStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Error(writer, "oops", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	loader := Loader{Client: server.Client(), CacheDir: t.TempDir()}
+	_, err := loader.Load(context.Background(), urlSources(server.URL, nil))
+	var ioErr *errs.IOError
+	if !errors.As(err, &ioErr) {
+		t.Fatalf("expected IOError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), server.URL) || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("expected URL and status in error, got %q", err)
+	}
+}
+
+func TestLoadURLCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	loader := Loader{Client: server.Client(), CacheDir: t.TempDir()}
+	_, err := loader.Load(ctx, urlSources(server.URL, nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected request to be cancelled with context, got %v", err)
+	}
+}
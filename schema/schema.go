@@ -0,0 +1,209 @@
+// Package schema loads GraphQL schema from one or many files, so types can be split between files
+// and extended with "extend type" in any of them. A file can be SDL, introspection JSON
+// or TypeScript/JavaScript with SDL in gql`...` strings,
+// schema can be fetched from a URL with introspection query too
+package schema
+
+import (
+	"context"
+	"fmt"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"net/http"
+	"path/filepath"
+	"recodegen/config"
+	"recodegen/document"
+	"recodegen/errs"
+	"recodegen/introspection"
+	"strings"
+	"unicode/utf8"
+)
+
+// Loader loads schema from files and URLs
+type Loader struct {
+	// Client is used to run introspection query against schema URLs, a client with DefaultTimeout if not set
+	Client *http.Client
+	// CacheDir keeps introspection results of schema URLs, "recodegen" in the user cache directory if not set
+	CacheDir string
+	// Offline makes schema URLs load from CacheDir only, without any requests
+	Offline bool
+}
+
+// Load loads all sources as one schema with default Loader
+func Load(ctx context.Context, sources config.Sources) (*ast.Schema, error) {
+	return (&Loader{}).Load(ctx, sources)
+}
+
+// Load loads all files matching sources and all schema URLs as one schema,
+// ctx cancels introspection queries of schema URLs
+func (loader *Loader) Load(ctx context.Context, schemaSources config.Sources) (*ast.Schema, error) {
+	fileNames, err := FindFiles(schemaSources.Paths())
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]config.SourceOptions)
+	for _, schemaSource := range schemaSources {
+		options[schemaSource.Path] = schemaSource.Options
+	}
+
+	var sources []*ast.Source
+	for _, fileName := range fileNames {
+		var source *ast.Source
+		if IsURL(fileName) {
+			source, err = loader.loadURL(ctx, fileName, options[fileName])
+		} else {
+			source, err = loadSource(fileName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	// gqlparser reports only the second definition of a duplicate, so duplicates are found first
+	if err := checkDuplicates(sources); err != nil {
+		return nil, &errs.SchemaError{Err: err}
+	}
+
+	schemaAst, parseErr := gqlparser.LoadSchema(sources...)
+	if parseErr != nil {
+		return nil, &errs.SchemaError{Err: parseErr}
+	}
+	return schemaAst, nil
+}
+
+// FindFiles returns schema files matching patterns, URLs and patterns without glob characters are returned as is,
+// so a missing file is reported when it's read
+func FindFiles(patterns []string) ([]string, error) {
+	var fileNames []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		var matches []string
+		if !IsURL(pattern) {
+			matches = document.FindFiles([]string{pattern})
+		}
+		if len(matches) == 0 {
+			if !IsURL(pattern) && strings.ContainsAny(pattern, "*?[{") {
+				return nil, &errs.SchemaError{Err: fmt.Errorf("no schema files match %s", pattern)}
+			}
+			matches = []string{pattern}
+		}
+		for _, fileName := range matches {
+			if seen[fileName] {
+				continue
+			}
+			seen[fileName] = true
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	if len(fileNames) == 0 {
+		return nil, &errs.SchemaError{Err: fmt.Errorf("no schema files are set in config")}
+	}
+	return fileNames, nil
+}
+
+// checkDuplicates returns an error naming both places where a type, a directive or a field is defined twice
+func checkDuplicates(sources []*ast.Source) error {
+	types := make(map[string]*ast.Position)
+	directives := make(map[string]*ast.Position)
+	fields := make(map[string]*ast.Position)
+
+	for _, source := range sources {
+		doc, err := parser.ParseSchema(source)
+		if err != nil {
+			return err
+		}
+		for _, def := range doc.Definitions {
+			if previous, isFound := types[def.Name]; isFound {
+				return duplicateError(def.Position, previous, "type %s", def.Name)
+			}
+			types[def.Name] = def.Position
+		}
+		for _, dir := range doc.Directives {
+			// builtin directives can be declared again, gqlparser keeps the first declaration
+			if introspection.IsBuiltinDirective(dir.Name) {
+				continue
+			}
+			if previous, isFound := directives[dir.Name]; isFound {
+				return duplicateError(dir.Position, previous, "directive %s", dir.Name)
+			}
+			directives[dir.Name] = dir.Position
+		}
+		for _, def := range append(append(ast.DefinitionList{}, doc.Definitions...), doc.Extensions...) {
+			for _, field := range def.Fields {
+				key := def.Name + "." + field.Name
+				if previous, isFound := fields[key]; isFound {
+					return duplicateError(field.Position, previous, "field %s", key)
+				}
+				fields[key] = field.Position
+			}
+		}
+	}
+	return nil
+}
+
+func duplicateError(position *ast.Position, previous *ast.Position, format string, args ...interface{}) error {
+	name := fmt.Sprintf(format, args...)
+	return gqlerror.ErrorPosf(position, "Cannot redeclare %s, it's already defined in %s:%d:%d.",
+		name, previous.Src.Name, previous.Line, previous.Column)
+}
+
+// schema in files with these extensions is written in gql`...` strings
+var codeExtensions = map[string]bool{
+	".ts":  true,
+	".tsx": true,
+	".mts": true,
+	".cts": true,
+	".js":  true,
+	".jsx": true,
+	".mjs": true,
+	".cjs": true,
+}
+
+// loadSource reads a schema file, introspection JSON is converted to SDL
+// and SDL in gql`...` strings of TypeScript/JavaScript files is plucked
+func loadSource(fileName string) (*ast.Source, error) {
+	content, err := document.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if codeExtensions[strings.ToLower(filepath.Ext(fileName))] {
+		content = joinDocuments(document.Pluck(fileName, content))
+	} else if introspection.IsIntrospection([]byte(content)) {
+		introspectionSchema, err := introspection.Parse([]byte(content))
+		if err != nil {
+			return nil, &errs.SchemaError{Err: fmt.Errorf("%s: unable to parse introspection JSON: %w", fileName, err)}
+		}
+		content = introspectionSchema.SDL()
+	}
+	return &ast.Source{
+		Name:  fileName,
+		Input: content,
+	}, nil
+}
+
+// joinDocuments concatenates plucked documents keeping their lines and columns,
+// so schema errors point to the place in the original file
+func joinDocuments(documents []*document.Document) string {
+	var output strings.Builder
+	line, column := 1, 1
+	for _, doc := range documents {
+		for ; line < doc.Line; line++ {
+			output.WriteString("\n")
+			column = 1
+		}
+		for ; column < doc.Column; column++ {
+			output.WriteString(" ")
+		}
+		output.WriteString(doc.Content)
+		line += strings.Count(doc.Content, "\n")
+		if lastLineStart := strings.LastIndex(doc.Content, "\n"); lastLineStart >= 0 {
+			column = 1 + utf8.RuneCountInString(doc.Content[lastLineStart+1:])
+		} else {
+			column += utf8.RuneCountInString(doc.Content)
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,44 @@
+package typescript
+
+import (
+	"github.com/vektah/gqlparser/v2/ast"
+	"sort"
+)
+
+// SchemaInfo holds data derived from a schema which is needed by both Schema and Operations generators.
+// Build it once per schema with NewSchemaInfo, it's never modified afterwards,
+// so it can be shared between generators running concurrently
+type SchemaInfo struct {
+	sortedTypeKeys []string
+	scalarNames    []string
+}
+
+func NewSchemaInfo(schemaAst *ast.Schema) *SchemaInfo {
+	info := &SchemaInfo{}
+
+	// Create a slice to hold the keys
+	info.sortedTypeKeys = make([]string, 0, len(schemaAst.Types))
+	for k := range schemaAst.Types {
+		info.sortedTypeKeys = append(info.sortedTypeKeys, k)
+	}
+
+	// Sort the keys
+	sort.Strings(info.sortedTypeKeys)
+
+	for _, key := range info.sortedTypeKeys {
+		def := schemaAst.Types[key]
+		if def.Kind == ast.Scalar {
+			info.scalarNames = append(info.scalarNames, def.Name)
+		}
+	}
+	return info
+}
+
+func (info *SchemaInfo) isScalar(typeName string) bool {
+	for _, scalar := range info.scalarNames {
+		if typeName == scalar {
+			return true
+		}
+	}
+	return false
+}
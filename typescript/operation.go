@@ -1,12 +1,9 @@
 package typescript
 
 import (
-	"fmt"
-	"github.com/bmatcuk/doublestar/v4"
-	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
-	"os"
 	"recodegen/config"
+	"recodegen/document"
 	"regexp"
 	"strings"
 	"unicode/utf8"
@@ -15,88 +12,48 @@ import (
 const defExportName = "Types"
 
 type Operations struct {
-	Config    *config.CodegenSchemaEntryConfig
-	Ast       *ast.Schema
+	Config *config.CodegenSchemaEntryConfig
+	Ast    *ast.Schema
+	// Info is built from Ast if not set, set it to share it between generators of the same schema
+	Info *SchemaInfo
+	// Query is a validated query document with all operations and fragments,
+	// it's loaded from Config.Documents if not set
+	Query     *ast.QueryDocument
 	fragments map[string]*ast.FragmentSpread
 }
 
-func (operations *Operations) String() string {
+func (operations *Operations) Generate() (string, error) {
 	typesPath := ""
 
-	// Load scalars if not loaded yet
-	if len(*scalarNames) == 0 {
-		schema := Schema{Ast: operations.Ast}
-		sortedTypeKeys := schema.getSortedTypeKeys()
-		for _, key := range *sortedTypeKeys {
-			def := schema.Ast.Types[key]
-			if def.Kind == ast.Scalar {
-				*scalarNames = append(*scalarNames, def.Name)
-			}
-		}
+	if operations.Info == nil {
+		operations.Info = NewSchemaInfo(operations.Ast)
 	}
+	genConfig := entryConfig(operations.Config)
 
-	if operations.Config.Preset == "import-types" {
-		typesPath = operations.Config.PresetConfig["typesPath"]
-	}
-	files := findFiles(operations.Config.Documents)
-	//fmt.Printf("%s\n", files)
-	srcOps := extractOperationsFromFiles(files)
-	dstOps := operations.generateOperations(srcOps, typesPath)
-	return dstOps
-}
-
-func findFiles(patterns []string) []string {
-	var output []string
-	fsys := os.DirFS(".")
-	for _, pattern := range patterns {
-		matches, _ := doublestar.Glob(fsys, pattern)
-		for _, fileName := range matches {
-			output = append(output, fileName)
-		}
-	}
-	return output
-}
-
-func extractOperationsFromFiles(fileNames []string) string {
-	// For each file...
-	output := ""
-	for _, fileName := range fileNames {
-		output += findOperationInFile(fileName)
+	if err := checkScalars(operations.Info.scalarNames, genConfig.Config); err != nil {
+		return "", err
 	}
-	return output
-}
 
-func findOperationInFile(fileName string) string {
-	output := ""
-	fileContent := getFileContent(fileName)
-	re := regexp.MustCompile("(?s)gql`(.*?)`")
-	matches := re.FindAllStringSubmatch(fileContent, -1)
-	if len(matches) == 0 {
-		return output
+	if genConfig.Preset == "import-types" {
+		typesPath = genConfig.PresetConfig["typesPath"]
 	}
-	for _, match := range matches {
-		output += match[1]
-	}
-	return output
-}
-
-func getFileContent(fileName string) string {
-	schemaBytes, err := os.ReadFile(fileName)
-	if err != nil {
-		panic(err)
+	if operations.Query == nil {
+		documents, err := document.LoadSet(genConfig.Documents.Paths())
+		if err != nil {
+			return "", err
+		}
+		query, err := documents.LoadQuery(operations.Ast, documents.Select(genConfig.Documents.Paths()))
+		if err != nil {
+			return "", err
+		}
+		operations.Query = query
 	}
-
-	return string(schemaBytes)
+	dstOps := operations.generateOperations(operations.Query, typesPath)
+	return dstOps, nil
 }
 
-func (operations *Operations) generateOperations(queryStr string, typesPath string) string {
+func (operations *Operations) generateOperations(astQuery *ast.QueryDocument, typesPath string) string {
 	output := ""
-	// Parse the schema file
-	astQuery, parseErr := gqlparser.LoadQuery(operations.Ast, queryStr)
-	if parseErr != nil {
-		fmt.Printf("Error while scanning: %s\n", operations.Config.Documents)
-		panic(parseErr)
-	}
 	isImportTypes := false
 	if typesPath != "" {
 		isImportTypes = true
@@ -113,7 +70,7 @@ func (operations *Operations) generateOperations(queryStr string, typesPath stri
 
 func (operations *Operations) generateOperationStr(astOp *ast.OperationDefinition, isImportTypes bool) string {
 	operations.fragments = make(map[string]*ast.FragmentSpread)
-	opVars := generateOperationVars(astOp, isImportTypes)
+	opVars := operations.Info.generateOperationVars(astOp, isImportTypes)
 	ops := operations.generateOperation(astOp, isImportTypes)
 	opFragments := operations.generateFragmentTypes(isImportTypes)
 	return opFragments + opVars + ops
@@ -122,8 +79,13 @@ func (operations *Operations) generateOperationStr(astOp *ast.OperationDefinitio
 func (operations *Operations) generateFragmentTypes(isImportTypes bool) string {
 	output := ""
 	for _, fragment := range operations.fragments {
+		def := operations.Ast.Types[fragment.Definition.TypeCondition]
 		output += "export type " + fragment.Name + "Fragment = {\n"
-		output += operations.generateFragmentSpreadField(fragment, isImportTypes)
+		if def.IsAbstractType() && hasTypeConditions(fragment.Definition.SelectionSet, def) {
+			output += operations.generateVariants(fragment.Definition.SelectionSet, def, isImportTypes)
+		} else {
+			output += operations.generateFragmentSpreadField(fragment, def, isImportTypes)
+		}
 		output += "\n};\n"
 	}
 	return output
@@ -162,7 +124,7 @@ func (operations *Operations) generateFragmentTypes(isImportTypes bool) string {
 //	return arr
 //}
 
-func generateOperationVars(astOp *ast.OperationDefinition, isImportTypes bool) string {
+func (info *SchemaInfo) generateOperationVars(astOp *ast.OperationDefinition, isImportTypes bool) string {
 	operationVars := ""
 	if isImportTypes {
 		operationVars = "export type " + normalizeOpName(astOp.Name) + UcFirst(string(astOp.Operation)) +
@@ -172,7 +134,7 @@ func generateOperationVars(astOp *ast.OperationDefinition, isImportTypes bool) s
 	}
 
 	for _, varDef := range astOp.VariableDefinitions {
-		operationVars += spacing + generateVariable(varDef, isImportTypes) + "\n"
+		operationVars += spacing + info.generateVariable(varDef, isImportTypes) + "\n"
 	}
 	operationVars += "}>;"
 	return operationVars
@@ -192,25 +154,26 @@ func (operations *Operations) generateOperation(astOp *ast.OperationDefinition,
 	if astOp.Operation == "mutation" {
 		output += "__typename?: 'mutation_root',\n"
 	}
-	for _, selection := range astOp.SelectionSet {
-		// ast.Selection
-		innerAstField, isField := selection.(*ast.Field)
-		if isField {
-			output += operations.generateOpField(innerAstField, isImportTypes)
-		}
-	}
+	output += operations.generateSelectionSet(astOp.SelectionSet, operations.getRootType(astOp), isImportTypes)
 	output += "}>;\n"
 	return output
 }
 
-func (operations *Operations) generateFragmentSpreadField(astFragmentSpread *ast.FragmentSpread, isImportTypes bool) string {
+func (operations *Operations) getRootType(astOp *ast.OperationDefinition) *ast.Definition {
+	switch astOp.Operation {
+	case ast.Mutation:
+		return operations.Ast.Mutation
+	case ast.Subscription:
+		return operations.Ast.Subscription
+	}
+	return operations.Ast.Query
+}
+
+func (operations *Operations) generateFragmentSpreadField(astFragmentSpread *ast.FragmentSpread, def *ast.Definition, isImportTypes bool) string {
 	output := ""
 	// list of fields: fieldName: fieldType
-	for _, fragment := range astFragmentSpread.Definition.SelectionSet {
-		innerAstField, isField := fragment.(*ast.Field)
-		if isField {
-			output += operations.generateOpField(innerAstField, isImportTypes) + "\n"
-		}
+	for _, innerAstField := range operations.collectFields(astFragmentSpread.Definition.SelectionSet, def) {
+		output += operations.generateOpField(innerAstField, isImportTypes) + "\n"
 	}
 	//__typename: astFragmentSpread.Definition.TypeCondition
 
@@ -226,7 +189,7 @@ func (operations *Operations) generateOpField(astField *ast.Field, isImportTypes
 	output := ""
 	if astField.SelectionSet == nil {
 		output += spacing + generateOpFieldName(astField) + ": " +
-			generateOpFieldType(astField.Definition.Type, isImportTypes) + ";"
+			operations.generateOpFieldType(astField.Definition.Type, isImportTypes) + ";"
 	} else {
 		opStr := ""
 		closeStr := ""
@@ -240,24 +203,129 @@ func (operations *Operations) generateOpField(astField *ast.Field, isImportTypes
 			closeStr += "};\n"
 		}
 		output += generateOpFieldName(astField) + ": " + opStr
-		output += "__typename?: '" + getUnderscoreTypeName(astField) + "',\n"
-		for _, selection := range astField.SelectionSet {
-			innerAstField, isField := selection.(*ast.Field)
-			if isField {
-				output += operations.generateOpField(innerAstField, isImportTypes)
-			} else {
-				astFragmentSpread, isFragmentSpread := selection.(*ast.FragmentSpread)
-				if isFragmentSpread {
-					operations.addSpreadFragment(astFragmentSpread)
-					output += operations.generateFragmentSpreadField(astFragmentSpread, isImportTypes)
-				}
-			}
+		def := operations.Ast.Types[astField.Definition.Type.Name()]
+		if def.IsAbstractType() && hasTypeConditions(astField.SelectionSet, def) {
+			output += operations.generateVariants(astField.SelectionSet, def, isImportTypes)
+		} else {
+			output += "__typename?: " + operations.typenameType(astField, def) + ",\n"
+			output += operations.generateSelectionSet(astField.SelectionSet, def, isImportTypes)
 		}
 		output += closeStr
 	}
 	return output
 }
 
+// generateSelectionSet generates fields of a selection set for the given object type,
+// fragments are only included if their type condition matches the type
+func (operations *Operations) generateSelectionSet(selectionSet ast.SelectionSet, def *ast.Definition, isImportTypes bool) string {
+	output := ""
+	for _, innerAstField := range operations.collectFields(selectionSet, def) {
+		output += operations.generateOpField(innerAstField, isImportTypes)
+	}
+	return output
+}
+
+// generateVariants generates one object per possible type of an interface or union queried with type conditions,
+// { __typename: 'User', ... } | { __typename: 'Post', ... } so result can be narrowed by __typename
+func (operations *Operations) generateVariants(selectionSet ast.SelectionSet, def *ast.Definition, isImportTypes bool) string {
+	var variants []string
+	for _, possibleType := range operations.Ast.GetPossibleTypes(def) {
+		variant := "__typename: '" + possibleType.Name + "',\n"
+		variant += operations.generateSelectionSet(selectionSet, possibleType, isImportTypes)
+		variants = append(variants, variant)
+	}
+	return strings.Join(variants, "} | {\n")
+}
+
+// collectFields flattens fragments of a selection set into a list of fields, fields with the same response key
+// requested directly and via fragments are generated once with their selection sets merged
+func (operations *Operations) collectFields(selectionSet ast.SelectionSet, def *ast.Definition) []*ast.Field {
+	return operations.appendFields(nil, map[string]int{}, selectionSet, def)
+}
+
+// appendFields appends fields of a selection set to fields, positions keeps index of each response key in fields
+func (operations *Operations) appendFields(fields []*ast.Field, positions map[string]int, selectionSet ast.SelectionSet, def *ast.Definition) []*ast.Field {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			position, seen := positions[selection.Alias]
+			if !seen {
+				positions[selection.Alias] = len(fields)
+				fields = append(fields, selection)
+			} else if selection.SelectionSet != nil {
+				// copy the field, so fragments shared between operations are left untouched
+				merged := *fields[position]
+				merged.SelectionSet = append(append(ast.SelectionSet{}, merged.SelectionSet...), selection.SelectionSet...)
+				fields[position] = &merged
+			}
+		case *ast.FragmentSpread:
+			if operations.isTypeConditionMatch(selection.Definition.TypeCondition, def) {
+				operations.addSpreadFragment(selection)
+				fields = operations.appendFields(fields, positions, selection.Definition.SelectionSet, def)
+			}
+		case *ast.InlineFragment:
+			if operations.isTypeConditionMatch(selection.TypeCondition, def) {
+				fields = operations.appendFields(fields, positions, selection.SelectionSet, def)
+			}
+		}
+	}
+	return fields
+}
+
+// isTypeConditionMatch checks if fragment with the type condition like "... on Node" applies to the given type
+func (operations *Operations) isTypeConditionMatch(typeCondition string, def *ast.Definition) bool {
+	if typeCondition == "" || typeCondition == def.Name {
+		return true
+	}
+	conditionDef := operations.Ast.Types[typeCondition]
+	if conditionDef == nil || !conditionDef.IsAbstractType() {
+		return false
+	}
+	for _, possibleType := range operations.Ast.GetPossibleTypes(conditionDef) {
+		if possibleType.Name == def.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTypeConditions checks if selection set has fragments on types other than the given one,
+// including the ones nested in fragments on the type itself
+func hasTypeConditions(selectionSet ast.SelectionSet, def *ast.Definition) bool {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.FragmentSpread:
+			if selection.Definition.TypeCondition != def.Name ||
+				hasTypeConditions(selection.Definition.SelectionSet, def) {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selection.TypeCondition != "" && selection.TypeCondition != def.Name ||
+				hasTypeConditions(selection.SelectionSet, def) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typenameType returns type of __typename, it's a union of possible type names like 'User' | 'Post'
+// for interfaces and unions, because the server returns the name of the object type
+func (operations *Operations) typenameType(astField *ast.Field, def *ast.Definition) string {
+	if !def.IsAbstractType() {
+		return "'" + getUnderscoreTypeName(astField) + "'"
+	}
+	var names []string
+	for _, possibleType := range operations.Ast.GetPossibleTypes(def) {
+		names = append(names, "'"+possibleType.Name+"'")
+	}
+	if len(names) == 0 {
+		// interface without implementations can't be returned at all
+		return "never"
+	}
+	return strings.Join(names, " | ")
+}
+
 func getUnderscoreTypeName(astField *ast.Field) string {
 	if astField.Definition.Type.NamedType != "" {
 		return astField.Definition.Type.NamedType
@@ -322,7 +390,7 @@ func fixTitleCase(input string) string {
 	return output
 }
 
-func generateVariable(varDef *ast.VariableDefinition, isImportTypes bool) string {
+func (info *SchemaInfo) generateVariable(varDef *ast.VariableDefinition, isImportTypes bool) string {
 	output := varDef.Variable
 	if varDef.Type.NonNull == false {
 		output += "?: "
@@ -330,15 +398,15 @@ func generateVariable(varDef *ast.VariableDefinition, isImportTypes bool) string
 		output += ": "
 	}
 	if isImportTypes {
-		output += generateFieldTypeImported(varDef.Type) + ";"
+		output += info.generateFieldTypeImported(varDef.Type) + ";"
 	} else {
-		output += generateFieldType(varDef.Type) + ";"
+		output += info.generateFieldType(varDef.Type, scalarInput) + ";"
 	}
 	return output
 }
 
-func generateFieldTypeImported(astType *ast.Type) string {
-	normalName := wrapScalar(astType.Name())
+func (info *SchemaInfo) generateFieldTypeImported(astType *ast.Type) string {
+	normalName := info.wrapScalar(astType.Name(), scalarInput)
 	normalName = defExportName + "." + normalName
 
 	if astType.NamedType != "" {
@@ -356,8 +424,8 @@ func generateFieldTypeImported(astType *ast.Type) string {
 	return "Array<" + normalName + ">"
 }
 
-func generateOpFieldType(astType *ast.Type, isImportTypes bool) string {
-	normalName := wrapOpScalar(astType.Name(), isImportTypes)
+func (operations *Operations) generateOpFieldType(astType *ast.Type, isImportTypes bool) string {
+	normalName := operations.wrapOpScalar(astType.Name(), isImportTypes)
 
 	if astType.NamedType != "" {
 		if astType.NonNull == false {
@@ -366,6 +434,11 @@ func generateOpFieldType(astType *ast.Type, isImportTypes bool) string {
 		return normalName
 	}
 
+	// mapped scalar type can be a union like "string | number"
+	if strings.ContainsAny(normalName, " |&") {
+		normalName = "(" + normalName + ")"
+	}
+
 	if astType.NonNull == false {
 		normalName = normalName + "[] | null"
 	}
@@ -373,23 +446,9 @@ func generateOpFieldType(astType *ast.Type, isImportTypes bool) string {
 	return normalName + "[]"
 }
 
-func wrapOpScalar(typeName string, isImportTypes bool) string {
-	for _, scalar := range *scalarNames {
-		if typeName == scalar {
-			switch scalar {
-			case "Boolean":
-				return "boolean"
-			case "String":
-				return "string"
-			case "Int":
-				return "number"
-			case "Float":
-				return "number"
-			case "ID":
-				return "string"
-			}
-			return "any"
-		}
+func (operations *Operations) wrapOpScalar(typeName string, isImportTypes bool) string {
+	if operations.Info.isScalar(typeName) {
+		return getScalarType(typeName, entryConfig(operations.Config).Config).Output
 	}
 	typeName = normalizedName(typeName)
 	if isImportTypes {
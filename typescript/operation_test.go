@@ -0,0 +1,105 @@
+package typescript
+
+import (
+	"github.com/vektah/gqlparser/v2"
+	"strings"
+	"testing"
+)
+
+// generateTestOperations generates operations of the query against the test schema,
+// whitespace is collapsed so expectations don't depend on indentation
+func generateTestOperations(t *testing.T, query string) string {
+	t.Helper()
+	schemaAst := loadTestSchema(t)
+	operations := Operations{Ast: schemaAst, Query: gqlparser.MustLoadQuery(schemaAst, query)}
+	output, err := operations.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return collapseSpaces(output)
+}
+
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func TestOperationTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "abstract type without type conditions has typename of possible types",
+			query: `query N { node(id: "1") { __typename id } }`,
+			want:  []string{"node?: { __typename?: 'User' | 'Post', id: string;};"},
+		},
+		{
+			name:  "object type has its own typename",
+			query: `query Me { me { id } }`,
+			want:  []string{"me: { __typename?: 'User', id: string;};"},
+		},
+		{
+			name:  "fragment on the abstract type itself isn't a type condition",
+			query: `query N { node(id: "1") { ... on Node { id } } }`,
+			want:  []string{"node?: { __typename?: 'User' | 'Post', id: string;};"},
+		},
+		{
+			name:  "inline fragments on union produce a variant per possible type",
+			query: `query S { search(text: "a") { ... on User { name } ... on Post { title } } }`,
+			want: []string{
+				"search: Array<{ __typename: 'User', name?: string | null;} | { __typename: 'Post', title?: string | null;}>;",
+			},
+		},
+		{
+			name:  "inline fragment on interface is included into every implementation",
+			query: `query S { search(text: "a") { ... on Node { id } ... on User { name } } }`,
+			want: []string{
+				"search: Array<{ __typename: 'User', id: string; name?: string | null;} | { __typename: 'Post', id: string;}>;",
+			},
+		},
+		{
+			name: "selection sets of the same field from fragment are merged",
+			query: `query Me { me { posts { id } ...P } }
+				fragment P on User { posts { title } }`,
+			want: []string{
+				"me: { __typename?: 'User', posts: Array<{ __typename?: 'Post', id: string; title?: string | null;}>; };",
+				"export type PFragment = { posts: Array<{ __typename?: 'Post', title?: string | null;}>; };",
+			},
+		},
+		{
+			name:  "aliased fields aren't merged",
+			query: `query Me { me { posts { id } other: posts { title } } }`,
+			want: []string{
+				"posts: Array<{ __typename?: 'Post', id: string;}>; other: Array<{ __typename?: 'Post', title?: string | null;}>;",
+			},
+		},
+		{
+			name: "type conditions nested in fragment on the abstract type",
+			query: `query N { node(id: "1") { ...NodeFields } }
+				fragment NodeFields on Node { id ... on User { name } ... on Post { title } }`,
+			want: []string{
+				"node?: { __typename: 'User', id: string; name?: string | null;} | { __typename: 'Post', id: string; title?: string | null;};",
+				"export type NodeFieldsFragment = { __typename: 'User', id: string; name?: string | null;} | " +
+					"{ __typename: 'Post', id: string; title?: string | null; };",
+			},
+		},
+		{
+			name:  "type conditions nested in inline fragment without type condition",
+			query: `query N { node(id: "1") { ... @include(if: true) { ... on User { name } } } }`,
+			want: []string{
+				"node?: { __typename: 'User', name?: string | null;} | { __typename: 'Post', };",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := generateTestOperations(t, test.query)
+			for _, want := range test.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected %q in output:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package typescript
+
+import (
+	"context"
+	"recodegen/plugin"
+)
+
+func init() {
+	plugin.Register("typescript", SchemaPlugin{})
+	plugin.Register("typescript-operations", OperationsPlugin{})
+}
+
+// SchemaPlugin generates types of all schema types, it's "typescript" plugin
+type SchemaPlugin struct{}
+
+func (SchemaPlugin) Generate(ctx context.Context, input *plugin.Input) (string, error) {
+	schema := Schema{
+		Ast:    input.Schema,
+		Config: input.Config,
+		Info:   getSchemaInfo(input),
+	}
+	return schema.Generate()
+}
+
+// OperationsPlugin generates types of operations and fragments from documents, it's "typescript-operations" plugin
+type OperationsPlugin struct{}
+
+func (OperationsPlugin) Generate(ctx context.Context, input *plugin.Input) (string, error) {
+	operations := Operations{
+		Ast:    input.Schema,
+		Config: input.Config,
+		Info:   getSchemaInfo(input),
+		Query:  input.Query,
+	}
+	return operations.Generate()
+}
+
+// getSchemaInfo returns SchemaInfo shared by all outputs of the same schema
+func getSchemaInfo(input *plugin.Input) *SchemaInfo {
+	if input.Cache == nil {
+		return NewSchemaInfo(input.Schema)
+	}
+	if info, isCached := input.Cache.Load(schemaInfoCacheKey{}); isCached {
+		return info.(*SchemaInfo)
+	}
+	info, _ := input.Cache.LoadOrStore(schemaInfoCacheKey{}, NewSchemaInfo(input.Schema))
+	return info.(*SchemaInfo)
+}
+
+type schemaInfoCacheKey struct{}
@@ -5,69 +5,83 @@ import (
 	"github.com/vektah/gqlparser/v2/ast"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"recodegen/config"
 	"regexp"
-	"sort"
 	"strings"
 )
 
 const spacing = "  "
 
-var scalarNames *[]string = &[]string{}
+// scalar type is referenced as Scalars['ID']['input'] in arguments and variables
+// and as Scalars['ID']['output'] in object fields
+const (
+	scalarInput  = "input"
+	scalarOutput = "output"
+)
+
+// TypeScript types of built-in scalars, any other scalar is "any" unless mapped in config
+var builtinScalars = map[string]string{
+	"ID":      "string",
+	"Int":     "number",
+	"Float":   "number",
+	"String":  "string",
+	"Boolean": "boolean",
+}
 
 type Schema struct {
-	Ast *ast.Schema
+	Ast    *ast.Schema
+	Config *config.CodegenSchemaEntryConfig
+	// Info is built from Ast if not set, set it to share it between generators of the same schema
+	Info *SchemaInfo
 }
 
-func (schema *Schema) String() string {
+func (schema *Schema) Generate() (string, error) {
 	// Traverse and process the AST (example: print type names)
 	var enumOnly = ""
 	var typesOnly = ""
 	var objectsOnly = ""
 
-	sortedTypeKeys := schema.getSortedTypeKeys()
-	if len(*scalarNames) == 0 {
-		for _, key := range *sortedTypeKeys {
-			def := schema.Ast.Types[key]
-			if def.Kind == ast.Scalar {
-				*scalarNames = append(*scalarNames, def.Name)
-			}
-		}
+	info := schema.Info
+	if info == nil {
+		info = NewSchemaInfo(schema.Ast)
+	}
+	if err := checkScalars(info.scalarNames, schema.pluginConfig()); err != nil {
+		return "", err
 	}
 
-	for _, key := range *sortedTypeKeys {
+	for _, key := range info.sortedTypeKeys {
 		def := schema.Ast.Types[key]
 		if def.Kind == ast.Enum {
 			enumOnly += genEnum(def)
 		}
 		if def.Kind == ast.InputObject {
-			typesOnly += genInputObject(def)
+			typesOnly += info.genInputObject(def)
 		}
 		if def.Kind == ast.Object {
-			objectsOnly += schema.generateObject(def)
+			objectsOnly += info.generateObject(def)
+		}
+		if def.Kind == ast.Interface {
+			objectsOnly += info.generateInterface(def)
+		}
+		if def.Kind == ast.Union {
+			objectsOnly += genUnion(def)
 		}
-		//if def.Kind == ast.Interface {
-		//	fmt.Printf("Interface: %s\n", def.Name)
-		//}
-		//if def.Kind == ast.Union {
-		//	fmt.Printf("Union: %s\n", def.Name)
-		//}
-
 	}
-	scalars := genScalars(*scalarNames)
-	return schema.getTypesHeader() + scalars + enumOnly + typesOnly + objectsOnly
+	scalars := genScalars(info.scalarNames, schema.pluginConfig())
+	return schema.getTypesHeader() + scalars + enumOnly + typesOnly + objectsOnly, nil
 }
 
-func (schema *Schema) getSortedTypeKeys() *[]string {
-	// Create a slice to hold the keys
-	keys := make([]string, 0, len(schema.Ast.Types))
-	for k := range schema.Ast.Types {
-		keys = append(keys, k)
-	}
-
-	// Sort the keys
-	sort.Strings(keys)
+// pluginConfig returns "config" section of the output, Config can be left unset when Schema is used as a library
+func (schema *Schema) pluginConfig() config.CodegenPluginConfig {
+	return entryConfig(schema.Config).Config
+}
 
-	return &keys
+// entryConfig returns the output config or an empty one if it's not set
+func entryConfig(entryConfig *config.CodegenSchemaEntryConfig) *config.CodegenSchemaEntryConfig {
+	if entryConfig == nil {
+		return &config.CodegenSchemaEntryConfig{}
+	}
+	return entryConfig
 }
 
 func (schema *Schema) getTypesHeader() string {
@@ -80,26 +94,47 @@ export type MakeMaybe<T, K extends keyof T> = Omit<T, K> & { [SubKey in K]: Mayb
 `
 }
 
-func genScalars(scalarNames []string) string {
+func genScalars(scalarNames []string, pluginConfig config.CodegenPluginConfig) string {
 	head := "export type Scalars = {\n"
 	body := ""
 	for _, name := range scalarNames {
-		switch name {
-		case "ID":
-			body += spacing + "ID: string;\n"
-		case "Int":
-			body += spacing + "Int: number;\n"
-		case "Float":
-			body += spacing + "Float: number;\n"
-		case "String":
-			body += spacing + "String: string;\n"
-		case "Boolean":
-			body += spacing + "Boolean: boolean;\n"
-		default:
-			body += spacing + name + ": any;\n"
+		scalarType := getScalarType(name, pluginConfig)
+		body += spacing + name + ": { input: " + scalarType.Input + "; output: " + scalarType.Output + "; }\n"
+	}
+	return head + body + "};\n"
+}
+
+// getScalarType returns TypeScript type for a scalar, mappings from config take precedence over built-in types
+func getScalarType(name string, pluginConfig config.CodegenPluginConfig) config.ScalarType {
+	if scalarType, ok := pluginConfig.Scalars[name]; ok {
+		return scalarType
+	}
+	if typeName, ok := builtinScalars[name]; ok {
+		return config.ScalarType{Input: typeName, Output: typeName}
+	}
+	if pluginConfig.DefaultScalarType != "" {
+		return config.ScalarType{Input: pluginConfig.DefaultScalarType, Output: pluginConfig.DefaultScalarType}
+	}
+	return config.ScalarType{Input: "any", Output: "any"}
+}
+
+// checkScalars returns an error listing all custom scalars without mapping if strictScalars is on
+func checkScalars(scalarNames []string, pluginConfig config.CodegenPluginConfig) error {
+	if pluginConfig.StrictScalars == nil || !*pluginConfig.StrictScalars {
+		return nil
+	}
+	var unmapped []string
+	for _, name := range scalarNames {
+		_, isMapped := pluginConfig.Scalars[name]
+		_, isBuiltin := builtinScalars[name]
+		if !isMapped && !isBuiltin {
+			unmapped = append(unmapped, name)
 		}
 	}
-	return head + body + "}\n"
+	if len(unmapped) > 0 {
+		return fmt.Errorf("strictScalars is on, but no mapping found in config.scalars for: %s", strings.Join(unmapped, ", "))
+	}
+	return nil
 }
 
 func genEnum(def *ast.Definition) string {
@@ -145,7 +180,7 @@ func normalizedName(snakeCase string) string {
 	return upperCamelCase
 }
 
-func genInputObject(def *ast.Definition) string {
+func (info *SchemaInfo) genInputObject(def *ast.Definition) string {
 	desc := generateDesc(def.Description)
 	if len(desc) > 0 {
 		desc += "\n"
@@ -153,14 +188,14 @@ func genInputObject(def *ast.Definition) string {
 	start := desc + "export type " + normalizedName(def.Name) + " = {\n"
 	var fields []string
 	for _, field := range def.Fields {
-		fieldStr := "  " + generateFieldName(field) + ": " + genInputFieldType(field.Type) + ";\n"
+		fieldStr := "  " + generateFieldName(field) + ": " + info.genInputFieldType(field.Type) + ";\n"
 		fields = append(fields, fieldStr)
 	}
 	return start + strings.Join(fields, "") + "};\n\n"
 }
 
-func generateFieldType(astType *ast.Type) string {
-	normalName := wrapScalar(astType.Name())
+func (info *SchemaInfo) generateFieldType(astType *ast.Type, scalarKind string) string {
+	normalName := info.wrapScalar(astType.Name(), scalarKind)
 
 	if astType.NamedType != "" {
 		if astType.NonNull == false {
@@ -177,13 +212,11 @@ func generateFieldType(astType *ast.Type) string {
 	return "Array<" + normalName + ">"
 }
 
-// given type like "Int" will wrap it into "Scalars['Int']"
+// given type like "Int" will wrap it into "Scalars['Int']['output']"
 // if given type is not scalar, return as is
-func wrapScalar(typeName string) string {
-	for _, scalar := range *scalarNames {
-		if typeName == scalar {
-			return "Scalars['" + typeName + "']"
-		}
+func (info *SchemaInfo) wrapScalar(typeName string, scalarKind string) string {
+	if info.isScalar(typeName) {
+		return "Scalars['" + typeName + "']['" + scalarKind + "']"
 	}
 	return normalizedName(typeName)
 }
@@ -199,8 +232,8 @@ func genNullable(astFieldDef *ast.FieldDefinition) string {
 	return "?"
 }
 
-func genInputFieldType(astType *ast.Type) string {
-	normalName := wrapScalar(astType.Name())
+func (info *SchemaInfo) genInputFieldType(astType *ast.Type) string {
+	normalName := info.wrapScalar(astType.Name(), scalarInput)
 
 	if astType.NamedType != "" {
 		if astType.NonNull == false {
@@ -242,18 +275,64 @@ func splitUnderscoredString(snakeCase string) []string {
 	return split
 }
 
-func (schema *Schema) generateObject(def *ast.Definition) string {
+func (info *SchemaInfo) generateObject(def *ast.Definition) string {
 	objectName := normalizedName(def.Name)
 	desc := generateDesc(def.Description)
-	header := fmt.Sprintf("\nexport type %s = {", objectName)
-	args := ""
+	header := fmt.Sprintf("\nexport type %s = %s{", objectName, genImplements(def.Interfaces))
 	if len(desc) > 0 {
 		header = "\n" + desc + header
 	}
 	body := "\n__typename?: '" + def.Name + "';\n"
 	footer := "\n};\n"
 
-	for _, field := range def.Fields {
+	fields, args := info.generateFields(objectName, def.Fields)
+	return header + body + fields + footer + args
+}
+
+// generateInterface generates an interface type, unlike objects interfaces have no __typename
+// since it's always one of the implementing objects
+func (info *SchemaInfo) generateInterface(def *ast.Definition) string {
+	interfaceName := normalizedName(def.Name)
+	desc := generateDesc(def.Description)
+	header := fmt.Sprintf("\nexport type %s = %s{\n", interfaceName, genImplements(def.Interfaces))
+	if len(desc) > 0 {
+		header = "\n" + desc + header
+	}
+	footer := "\n};\n"
+
+	fields, args := info.generateFields(interfaceName, def.Fields)
+	return header + fields + footer + args
+}
+
+// generates union as an alias of all its possible types
+// export type SearchResult = User | Post | Comment;
+func genUnion(def *ast.Definition) string {
+	desc := generateDesc(def.Description)
+	if len(desc) > 0 {
+		desc = "\n" + desc
+	}
+	var types []string
+	for _, name := range def.Types {
+		types = append(types, normalizedName(name))
+	}
+	return desc + "\nexport type " + normalizedName(def.Name) + " = " + strings.Join(types, " | ") + ";\n"
+}
+
+// given interfaces like ["Node", "Timestamped"] will return "Node & Timestamped & "
+// so object type can be declared as intersection with all the interfaces it implements
+func genImplements(interfaces []string) string {
+	output := ""
+	for _, name := range interfaces {
+		output += normalizedName(name) + " & "
+	}
+	return output
+}
+
+// generateFields returns field list of an object or interface and Args types for fields with arguments
+func (info *SchemaInfo) generateFields(parentName string, fieldDefs ast.FieldList) (string, string) {
+	body := ""
+	args := ""
+	for _, field := range fieldDefs {
 		if field.Name == "__schema" {
 			continue
 		}
@@ -261,17 +340,17 @@ func (schema *Schema) generateObject(def *ast.Definition) string {
 			continue
 		}
 		if len(field.Arguments) > 0 {
-			args += generateArgDefinition(objectName, field.Name, field.Arguments)
+			args += info.generateArgDefinition(parentName, field.Name, field.Arguments)
 		}
 		if len(field.Description) > 0 {
 			body += "\n" + spacing + generateDesc(field.Description)
 		}
-		body += "\n" + spacing + generateFieldName(field) + ": " + generateFieldType(field.Type) + ";"
+		body += "\n" + spacing + generateFieldName(field) + ": " + info.generateFieldType(field.Type, scalarOutput) + ";"
 	}
-	return header + body + footer + args
+	return body, args
 }
 
-func generateArgDefinition(parentName string, fieldName string, args []*ast.ArgumentDefinition) string {
+func (info *SchemaInfo) generateArgDefinition(parentName string, fieldName string, args []*ast.ArgumentDefinition) string {
 	output := "export type " + parentName + normalizedName(fieldName) + "Args = {\n"
 	fields := ""
 	for _, arg := range args {
@@ -284,7 +363,7 @@ func generateArgDefinition(parentName string, fieldName string, args []*ast.Argu
 		} else {
 			fields += ": "
 		}
-		fields += genInputFieldType(arg.Type) + ";\n"
+		fields += info.genInputFieldType(arg.Type) + ";\n"
 	}
 	output += fields + "};\n\n"
 	return output
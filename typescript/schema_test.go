@@ -0,0 +1,73 @@
+package typescript
+
+import (
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"strings"
+	"testing"
+)
+
+const testSDL = `
+scalar uuid
+
+type Query {
+  me: User!
+  node(id: ID!): Node
+  search(text: String!): [SearchResult!]!
+}
+
+interface Node {
+  id: ID!
+}
+
+type User implements Node {
+  id: ID!
+  uuid: uuid!
+  name: String
+  posts: [Post!]!
+}
+
+type Post implements Node {
+  id: ID!
+  title: String
+}
+
+union SearchResult = User | Post
+`
+
+func loadTestSchema(t *testing.T) *ast.Schema {
+	t.Helper()
+	return gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: testSDL})
+}
+
+func TestSchemaWithoutConfig(t *testing.T) {
+	schema := Schema{Ast: loadTestSchema(t)}
+	output, err := schema.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"uuid: { input: any; output: any; }",
+		"export type User = Node & {",
+		"export type SearchResult = User | Post;",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestOperationsWithoutConfig(t *testing.T) {
+	schemaAst := loadTestSchema(t)
+	query := gqlparser.MustLoadQuery(schemaAst, `query Me { me { uuid name } }`)
+	operations := Operations{Ast: schemaAst, Query: query}
+	output, err := operations.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"export type MeQuery = Exact<{", "uuid: any;", "name?: string | null;"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}